@@ -0,0 +1,162 @@
+// Package adminserver provides an internal HTTP server exposing pprof, expvar and runtime introspection endpoints.
+//
+// It lives apart from the runner, since net/http/pprof and expvar register their handlers on
+// http.DefaultServeMux when imported.
+package adminserver
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	runtimepprof "runtime/pprof"
+
+	"github.com/ashep/go-app/health"
+	"github.com/ashep/go-app/httpserver"
+	"github.com/ashep/go-app/prommetrics"
+	"github.com/ashep/go-app/runner"
+)
+
+const (
+	BuildInfoURLPath  = "/debug/buildinfo"
+	GoroutinesURLPath = "/debug/goroutines"
+)
+
+type options struct {
+	readiness bool
+}
+
+type Option func(*options)
+
+// WithReadiness makes the health endpoint respond with 503 until the runtime is marked ready.
+//
+// Use it only if the app calls Runtime.MarkReady, otherwise the endpoint never reports healthy.
+func WithReadiness() Option {
+	return func(o *options) {
+		o.readiness = true
+	}
+}
+
+// Server is an admin HTTP server serving pprof, expvar, a goroutine dump, the log level, build info,
+// the config snapshot, health and metrics endpoints. It must not be exposed publicly.
+type Server[CT any] struct {
+	rt   *runner.Runtime[CT]
+	addr string
+	opts options
+
+	srv    *httpserver.Server
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// New creates an admin server to be registered as a runtime component.
+//
+// The address can be overridden by APP_ADMIN_ADDR or <APPNAME>_ADMIN_ADDR env vars. The health endpoint
+// responds with 200 as long as the server is running, unless WithReadiness is given.
+func New[CT any](rt *runner.Runtime[CT], addr string, opts ...Option) *Server[CT] {
+	s := &Server[CT]{
+		rt:   rt,
+		addr: addr,
+	}
+
+	for _, opt := range opts {
+		opt(&s.opts)
+	}
+
+	return s
+}
+
+func (s *Server[CT]) Name() string {
+	return "admin server"
+}
+
+// Addr returns the address the server listens on. It is only valid after Start.
+func (s *Server[CT]) Addr() string {
+	return s.srv.Listener().Addr().String()
+}
+
+// Start starts serving in background, so the Server can be used as a runner component.
+func (s *Server[CT]) Start(ctx context.Context) error {
+	addr := s.addr
+	for _, prefix := range []string{"APP", s.rt.AppName2} {
+		if v := os.Getenv(prefix + "_ADMIN_ADDR"); v != "" {
+			addr = v
+		}
+	}
+
+	// Profile and trace captures last as long as requested, so responses are not limited in time
+	srv, err := httpserver.New(httpserver.WithAddr(addr), httpserver.WithWriteTimeout(0))
+	if err != nil {
+		return fmt.Errorf("create server: %w", err)
+	}
+
+	if err = srv.Listen(); err != nil {
+		return err
+	}
+
+	s.srv = srv
+	s.register()
+
+	// The server keeps serving while the app drains, until Stop is called
+	ctx, s.cancel = context.WithCancel(context.WithoutCancel(ctx))
+	s.done = make(chan struct{})
+
+	go func() {
+		defer close(s.done)
+		if err := srv.Run(ctx); err != nil {
+			s.rt.Log.Error().Err(err).Msg("admin server failed")
+		}
+	}()
+
+	s.rt.Log.Info().Str("addr", s.Addr()).Msg("admin server started")
+
+	return nil
+}
+
+// Stop stops the server started by Start.
+func (s *Server[CT]) Stop(ctx context.Context) error {
+	if s.cancel == nil {
+		return nil
+	}
+
+	s.cancel()
+
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *Server[CT]) register() {
+	s.srv.HandleFunc("/debug/pprof/", pprof.Index)
+	s.srv.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	s.srv.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	s.srv.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	s.srv.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	s.srv.Handle("/debug/vars", expvar.Handler())
+
+	s.srv.HandleFunc(GoroutinesURLPath, func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_ = runtimepprof.Lookup("goroutine").WriteTo(w, 2)
+	})
+
+	s.srv.HandleFunc(BuildInfoURLPath, func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(s.rt.BuildInfo())
+	})
+
+	var healthOpts []health.Option
+	if s.opts.readiness {
+		healthOpts = append(healthOpts, health.WithReadiness(s.rt))
+	}
+
+	s.rt.RegisterLogLevelServer(s.srv)
+	s.rt.RegisterConfigSnapshotServer(s.srv)
+	health.RegisterServer(s.srv, healthOpts...)
+	prommetrics.RegisterServer(s.rt.AppName, s.rt.AppVersion, s.srv)
+}
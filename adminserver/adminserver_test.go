@@ -0,0 +1,124 @@
+package adminserver_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/ashep/go-app/adminserver"
+	"github.com/ashep/go-app/runner"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testCfg struct {
+	Key string
+}
+
+func get(t *testing.T, addr, path string) (int, string) {
+	t.Helper()
+
+	res, err := http.Get("http://" + addr + path)
+	require.NoError(t, err)
+	defer func() {
+		_ = res.Body.Close()
+	}()
+
+	b, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	return res.StatusCode, string(b)
+}
+
+func TestServer(main *testing.T) {
+	main.Run("Ok", func(t *testing.T) {
+		ctx, ctxC := context.WithCancel(t.Context())
+		defer ctxC()
+
+		var addr string
+
+		err := runner.New(func(rt *runner.Runtime[testCfg]) error {
+			defer ctxC()
+
+			srv := adminserver.New(rt, "127.0.0.1:0")
+			require.NoError(t, rt.Register(srv))
+			addr = srv.Addr()
+
+			code, _ := get(t, addr, "/health")
+			assert.Equal(t, http.StatusOK, code)
+
+			code, body := get(t, addr, adminserver.BuildInfoURLPath)
+			assert.Equal(t, http.StatusOK, code)
+			assert.Contains(t, body, `"app":"adminserver"`)
+
+			code, body = get(t, addr, adminserver.GoroutinesURLPath)
+			assert.Equal(t, http.StatusOK, code)
+			assert.Contains(t, body, "goroutine ")
+
+			code, body = get(t, addr, runner.LogLevelURLPath)
+			assert.Equal(t, http.StatusOK, code)
+			assert.Contains(t, body, "info")
+
+			code, _ = get(t, addr, "/debug/pprof/")
+			assert.Equal(t, http.StatusOK, code)
+
+			code, body = get(t, addr, "/debug/vars")
+			assert.Equal(t, http.StatusOK, code)
+			assert.Contains(t, body, "memstats")
+
+			code, _ = get(t, addr, runner.ConfigSnapshotURLPath)
+			assert.Equal(t, http.StatusOK, code)
+
+			code, _ = get(t, addr, "/metrics")
+			assert.Equal(t, http.StatusOK, code)
+
+			return nil
+		}).RunContext(ctx)
+		require.NoError(t, err)
+
+		_, err = http.Get("http://" + addr + "/health") //nolint:bodyclose // must fail
+		require.Error(t, err)
+	})
+
+	main.Run("EnvAddr", func(t *testing.T) {
+		t.Setenv("APP_ADMIN_ADDR", "127.0.0.1:0")
+
+		ctx, ctxC := context.WithCancel(t.Context())
+		defer ctxC()
+
+		err := runner.New(func(rt *runner.Runtime[testCfg]) error {
+			defer ctxC()
+
+			srv := adminserver.New(rt, "127.0.0.1:1")
+			require.NoError(t, rt.Register(srv))
+			assert.NotEqual(t, "127.0.0.1:1", srv.Addr())
+
+			return nil
+		}).RunContext(ctx)
+		require.NoError(t, err)
+	})
+
+	main.Run("WithReadiness", func(t *testing.T) {
+		ctx, ctxC := context.WithCancel(t.Context())
+		defer ctxC()
+
+		err := runner.New(func(rt *runner.Runtime[testCfg]) error {
+			defer ctxC()
+
+			srv := adminserver.New(rt, "127.0.0.1:0", adminserver.WithReadiness())
+			require.NoError(t, rt.Register(srv))
+
+			code, _ := get(t, srv.Addr(), "/health")
+			assert.Equal(t, http.StatusServiceUnavailable, code)
+
+			rt.MarkReady()
+
+			code, _ = get(t, srv.Addr(), "/health")
+			assert.Equal(t, http.StatusOK, code)
+
+			return nil
+		}).RunContext(ctx)
+		require.NoError(t, err)
+	})
+}
@@ -12,8 +12,38 @@ type httpServer interface {
 	HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request))
 }
 
-func RegisterServer(srv httpServer) {
+// Readiness reports whether the app is ready to serve traffic, e.g. runner.Runtime.
+type Readiness interface {
+	IsReady() bool
+}
+
+type Option func(*config)
+
+// WithReadiness makes the health endpoint respond with 503 while r is not ready.
+func WithReadiness(r Readiness) Option {
+	return func(c *config) {
+		c.readiness = append(c.readiness, r)
+	}
+}
+
+type config struct {
+	readiness []Readiness
+}
+
+func RegisterServer(srv httpServer, opts ...Option) {
+	cfg := &config{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
 	srv.HandleFunc(URLPath, func(w http.ResponseWriter, r *http.Request) {
+		for _, rd := range cfg.readiness {
+			if !rd.IsReady() {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+		}
+
 		w.WriteHeader(http.StatusOK)
 	})
 }
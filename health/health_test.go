@@ -2,6 +2,7 @@ package health_test
 
 import (
 	"net/http"
+	"sync/atomic"
 	"testing"
 
 	"github.com/ashep/go-app/health"
@@ -21,3 +22,31 @@ func TestRegisterServer(main *testing.T) {
 		assert.Equal(t, http.StatusOK, res.StatusCode)
 	})
 }
+
+func TestRegisterServer_WithReadiness(main *testing.T) {
+	main.Run("Ok", func(t *testing.T) {
+		rd := &readinessMock{}
+
+		s := testhttpserver.New(t)
+		health.RegisterServer(s, health.WithReadiness(rd))
+		s.Run()
+
+		res, err := http.Get(s.URL(health.URLPath))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+
+		rd.ready.Store(true)
+
+		res, err = http.Get(s.URL(health.URLPath))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+	})
+}
+
+type readinessMock struct {
+	ready atomic.Bool
+}
+
+func (r *readinessMock) IsReady() bool {
+	return r.ready.Load()
+}
@@ -0,0 +1,186 @@
+package httpserver
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	RequestIDHeader    = "X-Request-ID"
+	ForwardedForHeader = "X-Forwarded-For"
+	RealIPHeader       = "X-Real-IP"
+)
+
+const (
+	maxRequestIDLength  = 128
+	requestIDRandomSize = 16
+)
+
+type requestIDCtxKey struct{}
+
+// AccessLog logs each request after it is served.
+func AccessLog(l zerolog.Logger) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rw := wrapResponseWriter(w)
+
+			next.ServeHTTP(rw, r)
+
+			ev := l.Info()
+			if rw.Status() >= http.StatusInternalServerError {
+				ev = l.Error()
+			}
+
+			if id := RequestIDFromContext(r.Context()); id != "" {
+				ev = ev.Str("request_id", id)
+			}
+
+			ev.
+				Str("method", r.Method).
+				Str("path", r.URL.Path).
+				Int("status", rw.Status()).
+				Int64("size", rw.size).
+				Dur("duration", time.Since(start)).
+				Str("remote_addr", r.RemoteAddr).
+				Str("user_agent", r.UserAgent()).
+				Msg("http request")
+		})
+	}
+}
+
+// Recover turns handler panics into 500 responses and logs them with the stack.
+func Recover(l zerolog.Logger) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rw := wrapResponseWriter(w)
+
+			defer func() {
+				v := recover()
+				if v == nil {
+					return
+				}
+
+				// Let the server abort the response
+				if err, ok := v.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+					panic(v)
+				}
+
+				ev := l.Error().
+					Str("panic", fmt.Sprint(v)).
+					Str("stack", string(debug.Stack())).
+					Str("method", r.Method).
+					Str("path", r.URL.Path)
+				if id := RequestIDFromContext(r.Context()); id != "" {
+					ev = ev.Str("request_id", id)
+				}
+				ev.Msg("http handler panicked")
+
+				if !rw.written() {
+					http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				}
+			}()
+
+			next.ServeHTTP(rw, r)
+		})
+	}
+}
+
+// RequestID takes the request ID from the X-Request-ID header or generates a new one, puts it to the request
+// context and to the response header.
+func RequestID() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := r.Header.Get(RequestIDHeader)
+			if id == "" || len(id) > maxRequestIDLength {
+				id = newRequestID()
+			}
+
+			w.Header().Set(RequestIDHeader, id)
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIDCtxKey{}, id)))
+		})
+	}
+}
+
+// RequestIDFromContext returns the request ID set by the RequestID middleware.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDCtxKey{}).(string)
+	return id
+}
+
+func newRequestID() string {
+	b := make([]byte, requestIDRandomSize)
+	_, _ = rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
+
+// RealIP replaces the request remote address with the client IP taken from X-Forwarded-For or X-Real-IP headers
+// if the request comes from one of the trusted proxies. X-Forwarded-For is processed from right to left
+// skipping trusted proxies, so the client cannot spoof its address.
+func RealIP(trustedProxies ...netip.Prefix) Middleware {
+	trusted := func(ip netip.Addr) bool {
+		for _, p := range trustedProxies {
+			if p.Contains(ip.Unmap()) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			host, _, err := net.SplitHostPort(r.RemoteAddr)
+			if err != nil {
+				host = r.RemoteAddr
+			}
+
+			remoteIP, err := netip.ParseAddr(host)
+			if err != nil || !trusted(remoteIP) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if ip, ok := clientIP(r.Header, trusted); ok {
+				r.RemoteAddr = ip.String()
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func clientIP(h http.Header, trusted func(netip.Addr) bool) (netip.Addr, bool) {
+	var hops []string
+	for _, v := range h.Values(ForwardedForHeader) {
+		hops = append(hops, strings.Split(v, ",")...)
+	}
+
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			return netip.Addr{}, false
+		}
+
+		if !trusted(ip) {
+			return ip.Unmap(), true
+		}
+	}
+
+	if ip, err := netip.ParseAddr(strings.TrimSpace(h.Get(RealIPHeader))); err == nil {
+		return ip.Unmap(), true
+	}
+
+	return netip.Addr{}, false
+}
@@ -6,9 +6,18 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
+// DefaultAddr is the address a server listens on if neither an address nor a listener is set.
+const DefaultAddr = "127.0.0.1:9000"
+
+const unixAddrPrefix = "unix:"
+
 type Option func(*Server)
 
 func WithListener(lis net.Listener) Option {
@@ -17,13 +26,11 @@ func WithListener(lis net.Listener) Option {
 	}
 }
 
+// WithAddr sets the address to listen on: a TCP "host:port" or a Unix domain socket path prefixed with "unix:",
+// e.g. "unix:/run/app.sock". The address is bound by Listen or Run.
 func WithAddr(addr string) Option {
 	return func(s *Server) {
-		lis, err := net.Listen("tcp", addr)
-		if err != nil {
-			panic(fmt.Sprintf("listen: %s", err))
-		}
-		WithListener(lis)(s)
+		s.addr = addr
 	}
 }
 
@@ -33,6 +40,13 @@ func WithRandomLocalAddr() Option {
 	}
 }
 
+// WithUnixSocketMode sets permissions of the Unix domain socket file. By default, they depend on the umask.
+func WithUnixSocketMode(mode os.FileMode) Option {
+	return func(s *Server) {
+		s.unixMode = mode
+	}
+}
+
 func WithHTTP1(v bool) Option {
 	return func(s *Server) {
 		s.srv.Protocols.SetHTTP1(v)
@@ -52,22 +66,43 @@ func WithUnencryptedHTTP2(v bool) Option {
 }
 
 type Server struct {
-	lis net.Listener
-	srv *http.Server
-	mux *http.ServeMux
+	addr     string
+	unixMode os.FileMode
+	lisMux   sync.Mutex
+	lis      net.Listener
+
+	srv     *http.Server
+	mux     *http.ServeMux
+	mws     []Middleware
+	handler atomic.Pointer[http.Handler] // mux wrapped with mws
+
+	metrics         bool
+	tls             *tlsConfig
+	shutdownTimeout time.Duration
 }
 
-func New(opts ...Option) *Server {
+// New creates a server. It doesn't bind the address until Listen or Run is called. If neither an address nor
+// a listener is set, DefaultAddr is used.
+func New(opts ...Option) (*Server, error) {
 	mux := http.NewServeMux()
 
 	s := &Server{
 		srv: &http.Server{
-			Handler:   mux,
-			Protocols: new(http.Protocols),
+			Protocols:         new(http.Protocols),
+			ReadHeaderTimeout: defaultReadHeaderTimeout,
+			ReadTimeout:       defaultReadTimeout,
+			WriteTimeout:      defaultWriteTimeout,
+			IdleTimeout:       defaultIdleTimeout,
+			MaxHeaderBytes:    defaultMaxHeaderBytes,
 		},
-		mux: mux,
+		mux:             mux,
+		shutdownTimeout: defaultShutdownTimeout,
 	}
 
+	s.srv.Handler = withClientCert(http.HandlerFunc(s.serveHTTP))
+	var h http.Handler = mux
+	s.handler.Store(&h)
+
 	for _, opt := range opts {
 		opt(s)
 	}
@@ -76,31 +111,125 @@ func New(opts ...Option) *Server {
 		s.srv.Protocols.SetHTTP1(true)
 	}
 
+	if s.lis == nil && s.addr == "" {
+		s.addr = DefaultAddr
+	}
+
 	if s.lis == nil {
-		WithAddr("127.0.0.1:9000")(s)
+		if path, ok := strings.CutPrefix(s.addr, unixAddrPrefix); ok {
+			if path == "" {
+				return nil, fmt.Errorf("invalid address %q: empty socket path", s.addr)
+			}
+		} else if _, _, err := net.SplitHostPort(s.addr); err != nil {
+			return nil, fmt.Errorf("invalid address %q: %w", s.addr, err)
+		}
+	}
+
+	return s, nil
+}
+
+// Listen binds the server address unless it is already bound.
+func (s *Server) Listen() error {
+	s.lisMux.Lock()
+	defer s.lisMux.Unlock()
+
+	if s.lis != nil {
+		return nil
+	}
+
+	path, ok := strings.CutPrefix(s.addr, unixAddrPrefix)
+	if !ok {
+		lis, err := net.Listen("tcp", s.addr)
+		if err != nil {
+			return fmt.Errorf("listen: %w", err)
+		}
+
+		s.lis = lis
+
+		return nil
+	}
+
+	// Remove a socket file left by a previous run
+	if fi, err := os.Stat(path); err == nil && fi.Mode()&os.ModeSocket != 0 {
+		if err = os.Remove(path); err != nil {
+			return fmt.Errorf("remove stale socket: %w", err)
+		}
+	}
+
+	lis, err := net.Listen("unix", path)
+	if err != nil {
+		return fmt.Errorf("listen: %w", err)
+	}
+
+	if s.unixMode != 0 {
+		if err = os.Chmod(path, s.unixMode); err != nil {
+			_ = lis.Close()
+			return fmt.Errorf("chmod socket: %w", err)
+		}
 	}
 
-	return s
+	s.lis = lis
+
+	return nil
 }
 
+// Listener returns the server listener or nil if the address is not bound yet.
 func (s *Server) Listener() net.Listener {
+	s.lisMux.Lock()
+	defer s.lisMux.Unlock()
+
 	return s.lis
 }
 
 func (s *Server) Handle(pattern string, handler http.Handler) {
-	s.mux.Handle(pattern, handler)
+	s.handle(pattern, handler)
 }
 
 func (s *Server) HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
-	s.mux.HandleFunc(pattern, handler)
+	s.handle(pattern, http.HandlerFunc(handler))
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	(*s.handler.Load()).ServeHTTP(w, r)
+}
+
+func (s *Server) handle(pattern string, handler http.Handler) {
+	if s.metrics {
+		handler = instrument(handler)
+	}
+
+	s.mux.Handle(pattern, handler)
 }
 
 func (s *Server) Run(ctx context.Context) error {
+	if err := s.Listen(); err != nil {
+		return err
+	}
+
+	serve := func() error {
+		return s.srv.Serve(s.lis)
+	}
+
+	if s.tls != nil {
+		cfg, err := s.tls.config()
+		if err != nil {
+			return err
+		}
+
+		s.srv.TLSConfig = cfg
+
+		go s.tls.watch(ctx)
+
+		serve = func() error {
+			return s.srv.ServeTLS(s.lis, "", "")
+		}
+	}
+
 	serveErr := make(chan error)
 
 	go func() {
 		defer close(serveErr)
-		err := s.srv.Serve(s.lis)
+		err := serve()
 		if errors.Is(err, http.ErrServerClosed) {
 			serveErr <- nil
 		} else if err != nil {
@@ -108,12 +237,22 @@ func (s *Server) Run(ctx context.Context) error {
 		}
 	}()
 
+	shutdownDone := make(chan struct{})
+
 	go func() {
+		defer close(shutdownDone)
 		<-ctx.Done()
-		sCtx, sCtxC := context.WithTimeout(context.Background(), time.Second*5)
+		sCtx, sCtxC := context.WithTimeout(context.Background(), s.shutdownTimeout)
 		defer sCtxC()
 		_ = s.srv.Shutdown(sCtx)
 	}()
 
-	return <-serveErr
+	if err := <-serveErr; err != nil {
+		return err
+	}
+
+	// Wait for in-flight requests
+	<-shutdownDone
+
+	return nil
 }
@@ -0,0 +1,99 @@
+package httpserver_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ashep/go-app/httpserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newServer creates a server listening on a random local port.
+func newServer(t *testing.T, opts ...httpserver.Option) *httpserver.Server {
+	t.Helper()
+
+	s, err := httpserver.New(append([]httpserver.Option{httpserver.WithRandomLocalAddr()}, opts...)...)
+	require.NoError(t, err)
+	require.NoError(t, s.Listen())
+
+	return s
+}
+
+func TestNew(main *testing.T) {
+	main.Run("InvalidAddr", func(t *testing.T) {
+		_, err := httpserver.New(httpserver.WithAddr("localhost"))
+		require.EqualError(t, err, `invalid address "localhost": address localhost: missing port in address`)
+
+		_, err = httpserver.New(httpserver.WithAddr("unix:"))
+		require.EqualError(t, err, `invalid address "unix:": empty socket path`)
+	})
+
+	main.Run("DeferredListen", func(t *testing.T) {
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer func() {
+			_ = lis.Close()
+		}()
+
+		s, err := httpserver.New(httpserver.WithAddr(lis.Addr().String()))
+		require.NoError(t, err)
+		assert.Nil(t, s.Listener())
+
+		require.ErrorContains(t, s.Listen(), "address already in use")
+		require.ErrorContains(t, s.Run(t.Context()), "address already in use")
+	})
+}
+
+func TestServer_Listen(main *testing.T) {
+	main.Run("UnixSocket", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "app.sock")
+
+		// Stale socket file
+		stale, err := net.Listen("unix", path)
+		require.NoError(t, err)
+		stale.(*net.UnixListener).SetUnlinkOnClose(false)
+		require.NoError(t, stale.Close())
+
+		s, err := httpserver.New(httpserver.WithAddr("unix:"+path), httpserver.WithUnixSocketMode(0o660))
+		require.NoError(t, err)
+		s.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusTeapot)
+		})
+
+		ctx, ctxC := context.WithCancel(t.Context())
+		runErr := make(chan error)
+		go func() {
+			runErr <- s.Run(ctx)
+		}()
+
+		c := &http.Client{Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, "unix", path)
+			},
+		}}
+
+		var res *http.Response
+		require.Eventually(t, func() bool {
+			res, err = c.Get("http://app/")
+			return err == nil
+		}, time.Second, time.Millisecond*10)
+		_ = res.Body.Close()
+		assert.Equal(t, http.StatusTeapot, res.StatusCode)
+
+		fi, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o660), fi.Mode().Perm())
+
+		ctxC()
+		require.NoError(t, <-runErr)
+
+		_, err = os.Stat(path)
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
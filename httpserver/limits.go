@@ -0,0 +1,125 @@
+package httpserver
+
+import (
+	"net/http"
+	"time"
+)
+
+const (
+	defaultReadHeaderTimeout = time.Second * 10
+	defaultReadTimeout       = time.Minute
+	defaultWriteTimeout      = time.Minute
+	defaultIdleTimeout       = time.Minute * 2
+	defaultMaxHeaderBytes    = 1 << 20
+	defaultShutdownTimeout   = time.Second * 5
+)
+
+// Config holds the server address, timeouts and limits. Zero values keep the defaults, while negative
+// ReadHeaderTimeout, ReadTimeout, WriteTimeout and IdleTimeout disable the respective timeouts. It can be embedded
+// into the app config and loaded by cfgloader.
+type Config struct {
+	Addr              string        `json:"addr" yaml:"addr"`
+	ReadHeaderTimeout time.Duration `json:"read_header_timeout" yaml:"read_header_timeout" split_words:"true"`
+	ReadTimeout       time.Duration `json:"read_timeout" yaml:"read_timeout" split_words:"true"`
+	WriteTimeout      time.Duration `json:"write_timeout" yaml:"write_timeout" split_words:"true"`
+	IdleTimeout       time.Duration `json:"idle_timeout" yaml:"idle_timeout" split_words:"true"`
+	MaxHeaderBytes    int           `json:"max_header_bytes" yaml:"max_header_bytes" split_words:"true"`
+	MaxBodyBytes      int64         `json:"max_body_bytes" yaml:"max_body_bytes" split_words:"true"`
+	ShutdownTimeout   time.Duration `json:"shutdown_timeout" yaml:"shutdown_timeout" split_words:"true"`
+}
+
+// WithConfig applies non-zero values of the config.
+func WithConfig(cfg Config) Option {
+	return func(s *Server) {
+		if cfg.Addr != "" {
+			WithAddr(cfg.Addr)(s)
+		}
+		if cfg.ReadHeaderTimeout != 0 {
+			WithReadHeaderTimeout(cfg.ReadHeaderTimeout)(s)
+		}
+		if cfg.ReadTimeout != 0 {
+			WithReadTimeout(cfg.ReadTimeout)(s)
+		}
+		if cfg.WriteTimeout != 0 {
+			WithWriteTimeout(cfg.WriteTimeout)(s)
+		}
+		if cfg.IdleTimeout != 0 {
+			WithIdleTimeout(cfg.IdleTimeout)(s)
+		}
+		if cfg.MaxHeaderBytes != 0 {
+			WithMaxHeaderBytes(cfg.MaxHeaderBytes)(s)
+		}
+		if cfg.MaxBodyBytes != 0 {
+			s.Use(MaxBodySize(cfg.MaxBodyBytes))
+		}
+		if cfg.ShutdownTimeout != 0 {
+			WithShutdownTimeout(cfg.ShutdownTimeout)(s)
+		}
+	}
+}
+
+// WithReadHeaderTimeout sets the time allowed to read request headers. Defaults to 10 seconds.
+// Zero makes the read timeout apply instead, negative d disables the timeout.
+func WithReadHeaderTimeout(d time.Duration) Option {
+	return func(s *Server) {
+		s.srv.ReadHeaderTimeout = d
+	}
+}
+
+// WithReadTimeout sets the time allowed to read an entire request, including the body. Defaults to 1 minute.
+// Zero or negative d disables the timeout.
+func WithReadTimeout(d time.Duration) Option {
+	return func(s *Server) {
+		s.srv.ReadTimeout = d
+	}
+}
+
+// WithWriteTimeout sets the time allowed to write a response. Defaults to 1 minute. Zero or negative d disables
+// the timeout.
+//
+// The timeout also applies to streaming responses and long-running handlers, such as profile captures. They can
+// extend it per request with http.ResponseController.SetWriteDeadline.
+func WithWriteTimeout(d time.Duration) Option {
+	return func(s *Server) {
+		s.srv.WriteTimeout = d
+	}
+}
+
+// WithIdleTimeout sets the time a keep-alive connection may be idle. Defaults to 2 minutes.
+// Zero makes the read timeout apply instead, negative d disables the timeout.
+func WithIdleTimeout(d time.Duration) Option {
+	return func(s *Server) {
+		s.srv.IdleTimeout = d
+	}
+}
+
+// WithMaxHeaderBytes sets the maximum size of request headers. Defaults to 1 MB.
+func WithMaxHeaderBytes(n int) Option {
+	return func(s *Server) {
+		s.srv.MaxHeaderBytes = n
+	}
+}
+
+// WithShutdownTimeout sets the time in-flight requests are given to complete once Run's context is done.
+// Defaults to 5 seconds.
+func WithShutdownTimeout(d time.Duration) Option {
+	return func(s *Server) {
+		s.shutdownTimeout = d
+	}
+}
+
+// MaxBodySize limits request bodies to n bytes. Reading beyond the limit fails, and requests declaring
+// a larger Content-Length are rejected with 413.
+func MaxBodySize(n int64) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength > n {
+				http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+				return
+			}
+
+			r.Body = http.MaxBytesReader(w, r.Body, n)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
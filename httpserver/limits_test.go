@@ -0,0 +1,136 @@
+package httpserver_test
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ashep/go-app/cfgloader"
+	"github.com/ashep/go-app/httpserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithConfig(main *testing.T) {
+	main.Run("Ok", func(t *testing.T) {
+		cfg := httpserver.Config{}
+		require.NoError(t, cfgloader.LoadYAML([]byte("read_header_timeout: 50ms\nmax_body_bytes: 4\n"), &cfg, nil))
+
+		s := newServer(t, httpserver.WithConfig(cfg))
+		s.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+			_, err := io.ReadAll(r.Body)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+			}
+		})
+
+		ctx, ctxC := context.WithCancel(t.Context())
+		defer ctxC()
+		go func() {
+			_ = s.Run(ctx)
+		}()
+
+		addr := s.Listener().Addr().String()
+		post := func(body io.Reader) int {
+			res, err := http.Post("http://"+addr+"/", "text/plain", body)
+			require.NoError(t, err)
+			_ = res.Body.Close()
+			return res.StatusCode
+		}
+
+		assert.Equal(t, http.StatusOK, post(strings.NewReader("1234")))
+		assert.Equal(t, http.StatusRequestEntityTooLarge, post(strings.NewReader("12345")))
+		assert.Equal(t, http.StatusBadRequest, post(io.MultiReader(strings.NewReader("12345")))) // unknown length
+
+		// Slow client
+		conn, err := net.Dial("tcp", addr)
+		require.NoError(t, err)
+		defer func() {
+			_ = conn.Close()
+		}()
+
+		_, err = conn.Write([]byte("GET / HTTP/1.1\r\n"))
+		require.NoError(t, err)
+
+		start := time.Now()
+		_, err = bufio.NewReader(conn).ReadByte()
+		require.ErrorIs(t, err, io.EOF)
+		assert.Less(t, time.Since(start), time.Second)
+	})
+}
+
+func TestWithWriteTimeout(main *testing.T) {
+	run := func(t *testing.T, opts ...httpserver.Option) error {
+		s := newServer(t, opts...)
+		s.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(time.Millisecond * 100)
+			_, _ = w.Write([]byte("ok"))
+		})
+
+		ctx, ctxC := context.WithCancel(t.Context())
+		defer ctxC()
+		go func() {
+			_ = s.Run(ctx)
+		}()
+
+		res, err := http.Get("http://" + s.Listener().Addr().String() + "/")
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = res.Body.Close()
+		}()
+
+		_, err = io.ReadAll(res.Body)
+
+		return err
+	}
+
+	main.Run("Ok", func(t *testing.T) {
+		require.Error(t, run(t, httpserver.WithWriteTimeout(time.Millisecond*50)))
+	})
+
+	main.Run("DisabledByConfig", func(t *testing.T) {
+		cfg := httpserver.Config{}
+		require.NoError(t, cfgloader.LoadYAML([]byte("write_timeout: -1s\n"), &cfg, nil))
+
+		require.NoError(t, run(t, httpserver.WithWriteTimeout(time.Millisecond*50), httpserver.WithConfig(cfg)))
+	})
+}
+
+func TestWithShutdownTimeout(main *testing.T) {
+	main.Run("Ok", func(t *testing.T) {
+		started := make(chan struct{})
+		s := newServer(t, httpserver.WithShutdownTimeout(time.Millisecond*50))
+		s.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+			close(started)
+			time.Sleep(time.Second)
+		})
+
+		ctx, ctxC := context.WithCancel(t.Context())
+		runErr := make(chan error)
+		go func() {
+			runErr <- s.Run(ctx)
+		}()
+
+		go func() {
+			res, err := http.Get("http://" + s.Listener().Addr().String() + "/")
+			if err == nil {
+				_ = res.Body.Close()
+			}
+		}()
+
+		<-started
+		start := time.Now()
+		ctxC()
+
+		require.NoError(t, <-runErr)
+		assert.GreaterOrEqual(t, time.Since(start), time.Millisecond*50)
+		assert.Less(t, time.Since(start), time.Millisecond*500)
+	})
+}
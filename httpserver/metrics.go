@@ -0,0 +1,32 @@
+package httpserver
+
+import (
+	"net/http"
+
+	"github.com/ashep/go-app/prommetrics"
+)
+
+// WithMetrics makes the server record Prometheus metrics of requests served by registered handlers:
+// request duration, in-flight requests and response size. The route pattern is used as the path label.
+func WithMetrics() Option {
+	return func(s *Server) {
+		s.metrics = true
+	}
+}
+
+func instrument(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		done := prommetrics.MeasureHTTPServerResponse(r, r.Pattern)
+		rw := wrapResponseWriter(w)
+
+		defer func() {
+			if v := recover(); v != nil {
+				done(http.StatusInternalServerError, rw.size)
+				panic(v)
+			}
+			done(rw.Status(), rw.size)
+		}()
+
+		next.ServeHTTP(rw, r)
+	})
+}
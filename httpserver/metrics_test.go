@@ -0,0 +1,89 @@
+package httpserver_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ashep/go-app/httpserver"
+	"github.com/ashep/go-app/prommetrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithMetrics(main *testing.T) {
+	main.Run("Ok", func(t *testing.T) {
+		s := newServer(t, httpserver.WithMetrics())
+		prommetrics.RegisterServer("an-app", "1.2.3", s)
+		s.HandleFunc("GET /items/{id}", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusTeapot)
+			_, _ = w.Write([]byte("Hello"))
+		})
+
+		ctx, ctxC := context.WithCancel(t.Context())
+		defer ctxC()
+		go func() {
+			_ = s.Run(ctx)
+		}()
+
+		baseURL := "http://" + s.Listener().Addr().String()
+
+		getMetrics := func() (string, error) {
+			res, err := http.Get(baseURL + prommetrics.URLPath)
+			if err != nil {
+				return "", err
+			}
+			defer func() {
+				_ = res.Body.Close()
+			}()
+
+			b, err := io.ReadAll(res.Body)
+
+			return string(b), err
+		}
+
+		var before string
+		require.Eventually(t, func() bool {
+			var err error
+			before, err = getMetrics()
+			return err == nil
+		}, time.Second, time.Millisecond*10)
+
+		res, err := http.Get(baseURL + "/items/1")
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+
+		m, err := getMetrics()
+		require.NoError(t, err)
+
+		const (
+			countSeries = `http_server_handler_duration_seconds_count{app="an-app",app_v="1.2.3",code="418",method="GET",path="GET /items/{id}"}`
+			sizeSeries  = `http_server_response_size_bytes_sum{app="an-app",app_v="1.2.3",code="418",method="GET",path="GET /items/{id}"}`
+		)
+
+		assert.Equal(t, metricValue(t, before, countSeries)+1, metricValue(t, m, countSeries))
+		assert.Equal(t, metricValue(t, before, sizeSeries)+5, metricValue(t, m, sizeSeries))
+		assert.NotContains(t, m, `host="`)
+		assert.Contains(t, m, `http_server_requests_in_flight{app="an-app",app_v="1.2.3",method="GET",path="GET /items/{id}"} 0`)
+		assert.Contains(t, m, `http_server_requests_in_flight{app="an-app",app_v="1.2.3",method="GET",path="`+prommetrics.URLPath+`"} 1`)
+	})
+}
+
+// metricValue returns the value of the series from the metrics exposition or zero if there is no such series.
+func metricValue(t *testing.T, metrics, series string) float64 {
+	t.Helper()
+
+	for _, line := range strings.Split(metrics, "\n") {
+		if v, ok := strings.CutPrefix(line, series+" "); ok {
+			f, err := strconv.ParseFloat(v, 64)
+			require.NoError(t, err)
+			return f
+		}
+	}
+
+	return 0
+}
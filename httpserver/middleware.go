@@ -0,0 +1,55 @@
+package httpserver
+
+import (
+	"net/http"
+)
+
+// Middleware wraps a handler to run code before and after it.
+type Middleware func(http.Handler) http.Handler
+
+// Chain wraps h with middlewares so the first one is the outermost.
+func Chain(h http.Handler, mw ...Middleware) http.Handler {
+	for i := len(mw) - 1; i >= 0; i-- {
+		h = mw[i](h)
+	}
+
+	return h
+}
+
+// Use adds middlewares applied to all requests served, including those not matching any route.
+// The first middleware added is the outermost one.
+//
+// It is safe to call Use while the server is running, but not concurrently with another Use call.
+func (s *Server) Use(mw ...Middleware) {
+	s.mws = append(s.mws, mw...)
+	h := Chain(s.mux, s.mws...)
+	s.handler.Store(&h)
+}
+
+// With returns a router registering routes on the server with additional middlewares applied.
+func (s *Server) With(mw ...Middleware) *Router {
+	return &Router{srv: s, mws: mw}
+}
+
+// Router registers routes with a set of middlewares applied.
+type Router struct {
+	srv *Server
+	mws []Middleware
+}
+
+// With returns a router with the middlewares added to the ones of r.
+func (r *Router) With(mw ...Middleware) *Router {
+	mws := make([]Middleware, 0, len(r.mws)+len(mw))
+	mws = append(mws, r.mws...)
+	mws = append(mws, mw...)
+
+	return &Router{srv: r.srv, mws: mws}
+}
+
+func (r *Router) Handle(pattern string, handler http.Handler) {
+	r.srv.handle(pattern, Chain(handler, r.mws...))
+}
+
+func (r *Router) HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	r.Handle(pattern, http.HandlerFunc(handler))
+}
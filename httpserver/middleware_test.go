@@ -0,0 +1,217 @@
+package httpserver_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/ashep/go-app/httpserver"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func header(name, value string) httpserver.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add(name, value)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func TestServer_Use(main *testing.T) {
+	main.Run("Ok", func(t *testing.T) {
+		s := newServer(t)
+		s.Use(header("X-Mw", "global1"), header("X-Mw", "global2"))
+		s.HandleFunc("/plain", func(w http.ResponseWriter, r *http.Request) {})
+		s.With(header("X-Mw", "route")).HandleFunc("/route", func(w http.ResponseWriter, r *http.Request) {})
+
+		ctx, ctxC := context.WithCancel(t.Context())
+		defer ctxC()
+		go func() {
+			_ = s.Run(ctx)
+		}()
+
+		get := func(path string) *http.Response {
+			var res *http.Response
+			require.Eventually(t, func() bool {
+				var err error
+				res, err = http.Get("http://" + s.Listener().Addr().String() + path)
+				return err == nil
+			}, time.Second, time.Millisecond*10)
+			_ = res.Body.Close()
+			return res
+		}
+
+		assert.Equal(t, []string{"global1", "global2"}, get("/plain").Header.Values("X-Mw"))
+		assert.Equal(t, []string{"global1", "global2", "route"}, get("/route").Header.Values("X-Mw"))
+
+		res := get("/notFound")
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+		assert.Equal(t, []string{"global1", "global2"}, res.Header.Values("X-Mw"))
+	})
+}
+
+func TestAccessLog(main *testing.T) {
+	main.Run("Ok", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		h := httpserver.Chain(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusTeapot)
+			_, _ = w.Write([]byte("Hello"))
+		}), httpserver.RequestID(), httpserver.AccessLog(zerolog.New(buf)))
+
+		req := httptest.NewRequest(http.MethodGet, "/foo", nil)
+		req.Header.Set(httpserver.RequestIDHeader, "aRequestID")
+		h.ServeHTTP(httptest.NewRecorder(), req)
+
+		assert.Contains(t, buf.String(), `{"level":"info","request_id":"aRequestID","method":"GET","path":"/foo","status":418,"size":5,"duration":`)
+	})
+
+	main.Run("Hijack", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		done := make(chan struct{})
+		h := httpserver.Chain(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			hj, ok := w.(http.Hijacker)
+			if !assert.True(t, ok) {
+				return
+			}
+
+			conn, rw, err := hj.Hijack()
+			if !assert.NoError(t, err) {
+				return
+			}
+			_, _ = rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nConnection: Upgrade\r\nUpgrade: foo\r\n\r\n")
+			assert.NoError(t, rw.Flush())
+			assert.NoError(t, conn.Close())
+		}), func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				defer close(done)
+				next.ServeHTTP(w, r)
+			})
+		}, httpserver.AccessLog(zerolog.New(buf)))
+
+		s := httptest.NewServer(h)
+		defer s.Close()
+
+		req, err := http.NewRequest(http.MethodGet, s.URL+"/ws", nil)
+		require.NoError(t, err)
+		req.Header.Set("Connection", "Upgrade")
+		req.Header.Set("Upgrade", "foo")
+
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		assert.Equal(t, http.StatusSwitchingProtocols, res.StatusCode)
+
+		<-done
+		assert.Contains(t, buf.String(), `"method":"GET","path":"/ws","status":101,"size":0`)
+	})
+}
+
+func TestRecover(main *testing.T) {
+	main.Run("Ok", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		h := httpserver.Chain(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic("aPanic")
+		}), httpserver.Recover(zerolog.New(buf)))
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/foo", nil))
+
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.Contains(t, buf.String(), `{"level":"error","panic":"aPanic","stack":"goroutine `)
+		assert.Contains(t, buf.String(), `"method":"GET","path":"/foo","message":"http handler panicked"}`)
+	})
+
+	main.Run("AlreadyWritten", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		h := httpserver.Chain(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusAccepted)
+			panic("aPanic")
+		}), httpserver.Recover(zerolog.New(buf)))
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/foo", nil))
+
+		assert.Equal(t, http.StatusAccepted, rec.Code)
+	})
+}
+
+func TestRequestID(main *testing.T) {
+	var gotID string
+	h := httpserver.Chain(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotID = httpserver.RequestIDFromContext(r.Context())
+	}), httpserver.RequestID())
+
+	main.Run("Generated", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		assert.Len(t, gotID, 32)
+		assert.Equal(t, gotID, rec.Header().Get(httpserver.RequestIDHeader))
+	})
+
+	main.Run("Propagated", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(httpserver.RequestIDHeader, "aRequestID")
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		assert.Equal(t, "aRequestID", gotID)
+		assert.Equal(t, "aRequestID", rec.Header().Get(httpserver.RequestIDHeader))
+	})
+}
+
+func TestRealIP(main *testing.T) {
+	var gotAddr string
+	h := httpserver.Chain(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAddr = r.RemoteAddr
+	}), httpserver.RealIP(netip.MustParsePrefix("10.0.0.0/8")))
+
+	serve := func(remoteAddr string, hdr map[string]string) string {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = remoteAddr
+		for k, v := range hdr {
+			req.Header.Set(k, v)
+		}
+		h.ServeHTTP(httptest.NewRecorder(), req)
+		return gotAddr
+	}
+
+	main.Run("TrustedProxy", func(t *testing.T) {
+		assert.Equal(t, "203.0.113.7", serve("10.0.0.1:1234", map[string]string{
+			httpserver.ForwardedForHeader: "198.51.100.1, 203.0.113.7, 10.0.0.2",
+		}))
+	})
+
+	main.Run("RealIPHeader", func(t *testing.T) {
+		assert.Equal(t, "203.0.113.7", serve("10.0.0.1:1234", map[string]string{
+			httpserver.RealIPHeader: "203.0.113.7",
+		}))
+	})
+
+	main.Run("UntrustedProxy", func(t *testing.T) {
+		assert.Equal(t, "192.0.2.1:1234", serve("192.0.2.1:1234", map[string]string{
+			httpserver.ForwardedForHeader: "203.0.113.7",
+		}))
+	})
+}
+
+func TestChain(main *testing.T) {
+	main.Run("Ok", func(t *testing.T) {
+		h := httpserver.Chain(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.WriteString(w, "ok")
+		}), header("X-Mw", "1"), header("X-Mw", "2"))
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		assert.Equal(t, []string{"1", "2"}, rec.Header().Values("X-Mw"))
+	})
+}
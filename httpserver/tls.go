@@ -0,0 +1,182 @@
+package httpserver
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+const defaultTLSReloadPeriod = time.Second * 10
+
+type clientCertCtxKey struct{}
+
+// WithTLS makes the server serve TLS using a certificate and a key loaded from PEM files.
+//
+// The files are checked for changes periodically and the certificate is reloaded without restarting the server.
+// If the new certificate cannot be loaded, the previous one keeps being used.
+func WithTLS(certFile, keyFile string) Option {
+	return func(s *Server) {
+		s.tlsCfg().certFile = certFile
+		s.tlsCfg().keyFile = keyFile
+	}
+}
+
+// WithClientCAs enables mutual TLS requiring clients to present a certificate signed by one of the CAs
+// loaded from a PEM file. The verified client certificate can be obtained by ClientCertFromContext.
+func WithClientCAs(caFile string) Option {
+	return func(s *Server) {
+		s.tlsCfg().clientCAFile = caFile
+	}
+}
+
+// WithMinTLSVersion sets the minimum TLS version accepted, e.g. tls.VersionTLS13. Defaults to tls.VersionTLS12.
+func WithMinTLSVersion(v uint16) Option {
+	return func(s *Server) {
+		s.tlsCfg().minVersion = v
+	}
+}
+
+// WithTLSReloadPeriod sets how often the certificate files are checked for changes. Defaults to 10 seconds.
+// A non-positive period disables reloading.
+func WithTLSReloadPeriod(d time.Duration) Option {
+	return func(s *Server) {
+		s.tlsCfg().reloadPeriod = d
+	}
+}
+
+// WithTLSReloadErrorHandler sets a function called when the certificate cannot be reloaded. By default,
+// reload errors are ignored and the previous certificate keeps being used.
+func WithTLSReloadErrorHandler(f func(err error)) Option {
+	return func(s *Server) {
+		s.tlsCfg().onReloadErr = f
+	}
+}
+
+// ClientCertFromContext returns the verified client certificate of a mutual TLS connection.
+func ClientCertFromContext(ctx context.Context) (*x509.Certificate, bool) {
+	c, ok := ctx.Value(clientCertCtxKey{}).(*x509.Certificate)
+	return c, ok
+}
+
+type tlsConfig struct {
+	certFile     string
+	keyFile      string
+	clientCAFile string
+	minVersion   uint16
+	reloadPeriod time.Duration
+	onReloadErr  func(err error)
+
+	cert    atomic.Pointer[tls.Certificate]
+	certMod [2]time.Time
+}
+
+func (s *Server) tlsCfg() *tlsConfig {
+	if s.tls == nil {
+		s.tls = &tlsConfig{
+			minVersion:   tls.VersionTLS12,
+			reloadPeriod: defaultTLSReloadPeriod,
+		}
+	}
+
+	return s.tls
+}
+
+// config loads the certificate and builds the TLS config.
+func (c *tlsConfig) config() (*tls.Config, error) {
+	if c.certFile == "" || c.keyFile == "" {
+		return nil, errors.New("tls certificate or key file is not set")
+	}
+
+	if _, err := c.reload(); err != nil {
+		return nil, err
+	}
+
+	cfg := &tls.Config{
+		MinVersion: c.minVersion,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return c.cert.Load(), nil
+		},
+	}
+
+	if c.clientCAFile != "" {
+		b, err := os.ReadFile(c.clientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read client ca file: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(b) {
+			return nil, errors.New("no certificates found in client ca file")
+		}
+
+		cfg.ClientCAs = pool
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return cfg, nil
+}
+
+// reload loads the certificate if its files have changed since the last load.
+func (c *tlsConfig) reload() (bool, error) {
+	var mod [2]time.Time
+	for i, p := range []string{c.certFile, c.keyFile} {
+		fi, err := os.Stat(p)
+		if err != nil {
+			return false, fmt.Errorf("stat tls file: %w", err)
+		}
+		mod[i] = fi.ModTime()
+	}
+
+	if c.cert.Load() != nil && mod == c.certMod {
+		return false, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+	if err != nil {
+		return false, fmt.Errorf("load tls certificate: %w", err)
+	}
+
+	c.cert.Store(&cert)
+	c.certMod = mod
+
+	return true, nil
+}
+
+// watch reloads the certificate periodically until ctx is done.
+func (c *tlsConfig) watch(ctx context.Context) {
+	if c.reloadPeriod <= 0 {
+		return
+	}
+
+	t := time.NewTicker(c.reloadPeriod)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			if _, err := c.reload(); err != nil && c.onReloadErr != nil {
+				c.onReloadErr(err)
+			}
+		}
+	}
+}
+
+// withClientCert puts the verified client certificate to the request context. It is the outermost handler,
+// so the certificate is available to all middlewares.
+func withClientCert(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS != nil && len(r.TLS.VerifiedChains) > 0 && len(r.TLS.VerifiedChains[0]) > 0 {
+			r = r.WithContext(context.WithValue(r.Context(), clientCertCtxKey{}, r.TLS.VerifiedChains[0][0]))
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,306 @@
+package httpserver_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ashep/go-app/httpserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	tls  tls.Certificate
+}
+
+func newTestCert(t *testing.T, cn string, parent *testCert) *testCert {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	require.NoError(t, err)
+
+	tpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+
+	parentCert, parentKey := tpl, key
+	if parent == nil {
+		tpl.IsCA = true
+		tpl.BasicConstraintsValid = true
+	} else {
+		parentCert, parentKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tpl, parentCert, &key.PublicKey, parentKey)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return &testCert{
+		cert: cert,
+		key:  key,
+		tls:  tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: cert},
+	}
+}
+
+func (c *testCert) write(t *testing.T, certFile, keyFile string) {
+	t.Helper()
+
+	keyDER, err := x509.MarshalECPrivateKey(c.key)
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.cert.Raw}), 0o600))
+	if keyFile != "" {
+		require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	}
+}
+
+func runTLSServer(t *testing.T, opts ...httpserver.Option) *httpserver.Server {
+	t.Helper()
+
+	s := newServer(t, opts...)
+	s.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if c, ok := httpserver.ClientCertFromContext(r.Context()); ok {
+			w.Header().Set("X-Client", c.Subject.CommonName)
+		}
+	})
+
+	ctx, ctxC := context.WithCancel(t.Context())
+	t.Cleanup(ctxC)
+
+	go func() {
+		_ = s.Run(ctx)
+	}()
+
+	return s
+}
+
+func tlsGet(s *httpserver.Server, cfg *tls.Config) (*http.Response, error) {
+	c := &http.Client{Transport: &http.Transport{TLSClientConfig: cfg}}
+
+	res, err := c.Get("https://" + s.Listener().Addr().String() + "/")
+	if err != nil {
+		return nil, err
+	}
+
+	_ = res.Body.Close()
+
+	return res, nil
+}
+
+func TestWithTLS(main *testing.T) {
+	main.Run("Reload", func(t *testing.T) {
+		dir := t.TempDir()
+		certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+
+		ca := newTestCert(t, "ca", nil)
+		newTestCert(t, "server1", ca).write(t, certFile, keyFile)
+
+		roots := x509.NewCertPool()
+		roots.AddCert(ca.cert)
+
+		s := runTLSServer(t, httpserver.WithTLS(certFile, keyFile), httpserver.WithTLSReloadPeriod(time.Millisecond*10))
+
+		var res *http.Response
+		require.Eventually(t, func() bool {
+			var err error
+			res, err = tlsGet(s, &tls.Config{RootCAs: roots})
+			return err == nil
+		}, time.Second, time.Millisecond*10)
+		assert.Equal(t, "server1", res.TLS.PeerCertificates[0].Subject.CommonName)
+
+		// Make sure mtime changes
+		time.Sleep(time.Millisecond * 10)
+		newTestCert(t, "server2", ca).write(t, certFile, keyFile)
+
+		assert.Eventually(t, func() bool {
+			res, err := tlsGet(s, &tls.Config{RootCAs: roots})
+			return err == nil && res.TLS.PeerCertificates[0].Subject.CommonName == "server2"
+		}, time.Second, time.Millisecond*10)
+	})
+
+	main.Run("ReloadError", func(t *testing.T) {
+		dir := t.TempDir()
+		certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+
+		ca := newTestCert(t, "ca", nil)
+		newTestCert(t, "server", ca).write(t, certFile, keyFile)
+
+		roots := x509.NewCertPool()
+		roots.AddCert(ca.cert)
+
+		reloadErr := make(chan error, 1)
+		s := runTLSServer(t,
+			httpserver.WithTLS(certFile, keyFile),
+			httpserver.WithTLSReloadPeriod(time.Millisecond*10),
+			httpserver.WithTLSReloadErrorHandler(func(err error) {
+				select {
+				case reloadErr <- err:
+				default:
+				}
+			}),
+		)
+
+		require.Eventually(t, func() bool {
+			_, err := tlsGet(s, &tls.Config{RootCAs: roots})
+			return err == nil
+		}, time.Second, time.Millisecond*10)
+
+		// Make sure mtime changes
+		time.Sleep(time.Millisecond * 10)
+		require.NoError(t, os.WriteFile(certFile, []byte("notACert"), 0o600))
+
+		select {
+		case err := <-reloadErr:
+			require.ErrorContains(t, err, "load tls certificate")
+		case <-time.After(time.Second):
+			t.Fatal("reload error was not reported")
+		}
+
+		res, err := tlsGet(s, &tls.Config{RootCAs: roots})
+		require.NoError(t, err)
+		assert.Equal(t, "server", res.TLS.PeerCertificates[0].Subject.CommonName)
+	})
+
+	main.Run("NoReload", func(t *testing.T) {
+		dir := t.TempDir()
+		certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+
+		ca := newTestCert(t, "ca", nil)
+		newTestCert(t, "server1", ca).write(t, certFile, keyFile)
+
+		roots := x509.NewCertPool()
+		roots.AddCert(ca.cert)
+
+		s := runTLSServer(t, httpserver.WithTLS(certFile, keyFile), httpserver.WithTLSReloadPeriod(0))
+
+		require.Eventually(t, func() bool {
+			_, err := tlsGet(s, &tls.Config{RootCAs: roots})
+			return err == nil
+		}, time.Second, time.Millisecond*10)
+
+		// Make sure mtime changes
+		time.Sleep(time.Millisecond * 10)
+		newTestCert(t, "server2", ca).write(t, certFile, keyFile)
+		time.Sleep(time.Millisecond * 50)
+
+		res, err := tlsGet(s, &tls.Config{RootCAs: roots})
+		require.NoError(t, err)
+		assert.Equal(t, "server1", res.TLS.PeerCertificates[0].Subject.CommonName)
+	})
+
+	main.Run("InvalidCert", func(t *testing.T) {
+		s, err := httpserver.New(httpserver.WithRandomLocalAddr(), httpserver.WithTLS("notExists.pem", "notExists.pem"))
+		require.NoError(t, err)
+		require.ErrorContains(t, s.Run(t.Context()), "stat tls file")
+	})
+
+	main.Run("MinVersion", func(t *testing.T) {
+		dir := t.TempDir()
+		certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+
+		ca := newTestCert(t, "ca", nil)
+		newTestCert(t, "server", ca).write(t, certFile, keyFile)
+
+		roots := x509.NewCertPool()
+		roots.AddCert(ca.cert)
+
+		s := runTLSServer(t, httpserver.WithTLS(certFile, keyFile), httpserver.WithMinTLSVersion(tls.VersionTLS13))
+
+		require.Eventually(t, func() bool {
+			_, err := tlsGet(s, &tls.Config{RootCAs: roots})
+			return err == nil
+		}, time.Second, time.Millisecond*10)
+
+		_, err := tlsGet(s, &tls.Config{RootCAs: roots, MaxVersion: tls.VersionTLS12})
+		require.ErrorContains(t, err, "protocol version")
+	})
+}
+
+func TestWithClientCAs(main *testing.T) {
+	main.Run("Ok", func(t *testing.T) {
+		dir := t.TempDir()
+		certFile, keyFile, caFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem"), filepath.Join(dir, "ca.pem")
+
+		ca := newTestCert(t, "ca", nil)
+		ca.write(t, caFile, "")
+		newTestCert(t, "server", ca).write(t, certFile, keyFile)
+		client := newTestCert(t, "aClient", ca)
+
+		roots := x509.NewCertPool()
+		roots.AddCert(ca.cert)
+
+		s := runTLSServer(t, httpserver.WithTLS(certFile, keyFile), httpserver.WithClientCAs(caFile))
+
+		var res *http.Response
+		require.Eventually(t, func() bool {
+			var err error
+			res, err = tlsGet(s, &tls.Config{RootCAs: roots, Certificates: []tls.Certificate{client.tls}})
+			return err == nil
+		}, time.Second, time.Millisecond*10)
+		assert.Equal(t, "aClient", res.Header.Get("X-Client"))
+
+		_, err := tlsGet(s, &tls.Config{RootCAs: roots})
+		require.Error(t, err)
+	})
+	main.Run("UseWhileRunning", func(t *testing.T) {
+		dir := t.TempDir()
+		certFile, keyFile, caFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem"), filepath.Join(dir, "ca.pem")
+
+		ca := newTestCert(t, "ca", nil)
+		ca.write(t, caFile, "")
+		newTestCert(t, "server", ca).write(t, certFile, keyFile)
+		client := newTestCert(t, "aClient", ca)
+
+		roots := x509.NewCertPool()
+		roots.AddCert(ca.cert)
+		cfg := &tls.Config{RootCAs: roots, Certificates: []tls.Certificate{client.tls}}
+
+		s := runTLSServer(t, httpserver.WithTLS(certFile, keyFile), httpserver.WithClientCAs(caFile))
+
+		require.Eventually(t, func() bool {
+			_, err := tlsGet(s, cfg)
+			return err == nil
+		}, time.Second, time.Millisecond*10)
+
+		s.Use(func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if c, ok := httpserver.ClientCertFromContext(r.Context()); ok {
+					w.Header().Set("X-Middleware-Client", c.Subject.CommonName)
+				}
+				next.ServeHTTP(w, r)
+			})
+		})
+
+		res, err := tlsGet(s, cfg)
+		require.NoError(t, err)
+		assert.Equal(t, "aClient", res.Header.Get("X-Middleware-Client"))
+		assert.Equal(t, "aClient", res.Header.Get("X-Client"))
+	})
+}
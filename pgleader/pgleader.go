@@ -0,0 +1,214 @@
+package pgleader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog"
+)
+
+type Option func(*Elector)
+
+// WithRetryInterval sets how often a follower tries to acquire the lock. Defaults to 5 seconds.
+func WithRetryInterval(d time.Duration) Option {
+	return func(e *Elector) {
+		e.retryInterval = d
+	}
+}
+
+// WithCheckInterval sets how often the leader checks its DB session is alive. Defaults to 5 seconds.
+func WithCheckInterval(d time.Duration) Option {
+	return func(e *Elector) {
+		e.checkInterval = d
+	}
+}
+
+// WithOnChange subscribes f to leadership changes.
+func WithOnChange(f func(isLeader bool)) Option {
+	return func(e *Elector) {
+		e.OnChange(f)
+	}
+}
+
+// Elector elects a single leader among app instances by holding a session-level Postgres advisory lock.
+type Elector struct {
+	pool          *pgxpool.Pool
+	name          string
+	key           int64
+	l             zerolog.Logger
+	retryInterval time.Duration
+	checkInterval time.Duration
+
+	leader atomic.Bool
+	mux    sync.Mutex
+	subs   []func(bool)
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func New(pool *pgxpool.Pool, name string, l zerolog.Logger, opts ...Option) *Elector {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(name))
+
+	e := &Elector{
+		pool:          pool,
+		name:          name,
+		key:           int64(h.Sum64()), //nolint:gosec // overflow is ok for a lock key
+		l:             l.With().Str("leader_lock", name).Logger(),
+		retryInterval: time.Second * 5,
+		checkInterval: time.Second * 5,
+	}
+
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	return e
+}
+
+func (e *Elector) Name() string {
+	return "pgleader:" + e.name
+}
+
+func (e *Elector) IsLeader() bool {
+	return e.leader.Load()
+}
+
+// OnChange subscribes f to leadership changes. It is called synchronously from the election loop.
+func (e *Elector) OnChange(f func(isLeader bool)) {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+
+	e.subs = append(e.subs, f)
+}
+
+// Run takes part in the election until ctx is done, after which the leadership is released.
+func (e *Elector) Run(ctx context.Context) error {
+	for {
+		if err := e.try(ctx); err != nil && ctx.Err() == nil {
+			e.l.Warn().Err(err).Msg("leader election failed")
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(e.retryInterval):
+		}
+	}
+}
+
+// Start runs the election in background, so the Elector can be used as a runner component.
+func (e *Elector) Start(ctx context.Context) error {
+	ctx, e.cancel = context.WithCancel(ctx)
+	e.done = make(chan struct{})
+
+	go func() {
+		defer close(e.done)
+		_ = e.Run(ctx)
+	}()
+
+	return nil
+}
+
+// Stop stops the election started by Start and releases the leadership.
+func (e *Elector) Stop(ctx context.Context) error {
+	if e.cancel == nil {
+		return nil
+	}
+
+	e.cancel()
+
+	select {
+	case <-e.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// try acquires the lock and holds it while the session is alive and ctx is not done.
+func (e *Elector) try(ctx context.Context) error {
+	conn, err := e.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("acquire connection: %w", err)
+	}
+
+	var ok bool
+	if err = conn.QueryRow(ctx, "SELECT pg_try_advisory_lock($1)", e.key).Scan(&ok); err != nil {
+		conn.Release()
+		return fmt.Errorf("try lock: %w", err)
+	}
+
+	if !ok {
+		conn.Release()
+		return nil
+	}
+
+	e.setLeader(true)
+
+	defer func() {
+		e.setLeader(false)
+		e.release(conn)
+	}()
+
+	t := time.NewTicker(e.checkInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-t.C:
+			if err := conn.Ping(ctx); err != nil && ctx.Err() == nil {
+				return fmt.Errorf("leader session lost: %w", err)
+			}
+		}
+	}
+}
+
+// release unlocks the lock and returns the connection to the pool.
+func (e *Elector) release(conn *pgxpool.Conn) {
+	ctx, ctxC := context.WithTimeout(context.Background(), time.Second*5)
+	defer ctxC()
+
+	var ok bool
+	err := conn.QueryRow(ctx, "SELECT pg_advisory_unlock($1)", e.key).Scan(&ok)
+	if err == nil && !ok {
+		err = errors.New("lock is not held")
+	}
+
+	if err != nil {
+		// Closing the session releases the lock anyway
+		e.l.Warn().Err(err).Msg("leader lock release failed, closing connection")
+		_ = conn.Conn().Close(ctx)
+	}
+
+	conn.Release()
+}
+
+func (e *Elector) setLeader(v bool) {
+	if e.leader.Swap(v) == v {
+		return
+	}
+
+	if v {
+		e.l.Info().Msg("leadership acquired")
+	} else {
+		e.l.Info().Msg("leadership released")
+	}
+
+	e.mux.Lock()
+	subs := e.subs
+	e.mux.Unlock()
+
+	for _, f := range subs {
+		f(v)
+	}
+}
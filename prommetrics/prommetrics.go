@@ -1,6 +1,7 @@
 package prommetrics
 
 import (
+	"errors"
 	"net/http"
 	"slices"
 	"strconv"
@@ -9,6 +10,7 @@ import (
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
@@ -28,7 +30,10 @@ var (
 	mux = sync.RWMutex{}
 
 	counters   = make(map[string]*prometheus.CounterVec)
+	gauges     = make(map[string]*prometheus.GaugeVec)
 	histograms = make(map[string]*prometheus.HistogramVec)
+
+	runtimeCollectorsOnce sync.Once
 )
 
 func RegisterServer(appN, appV string, srv httpServer) {
@@ -65,6 +70,46 @@ func MeasureHTTPServerRequest(req *http.Request, path string) func(int) {
 	}
 }
 
+// MeasureHTTPServerResponse counts the request as in-flight and returns a function which records the request
+// duration and the response size. The path must be a low-cardinality value, e.g. the matched route pattern.
+//
+// Unlike MeasureHTTPServerRequest, it doesn't label metrics with the client-controlled Host header.
+func MeasureHTTPServerResponse(req *http.Request, path string) func(statusCode int, size int64) {
+	inFlightLbs := prometheus.Labels{
+		"method": req.Method,
+		"path":   path,
+	}
+
+	inFlight := GetGauge("http_server_requests_in_flight", "HTTP server requests being served.", inFlightLbs).
+		With(inFlightLbs)
+	inFlight.Inc()
+
+	start := time.Now()
+
+	return func(statusCode int, size int64) {
+		inFlight.Dec()
+
+		lbs := prometheus.Labels{
+			"method": req.Method,
+			"path":   path,
+			"code":   strconv.Itoa(statusCode),
+		}
+
+		if appName != "" {
+			lbs["app"] = appName
+		}
+
+		if appVersion != "" {
+			lbs["app_v"] = appVersion
+		}
+
+		GetHistogram("http_server_handler_duration_seconds", "HTTP server handler duration.", lbs).
+			With(lbs).Observe(time.Since(start).Seconds())
+		GetHistogram("http_server_response_size_bytes", "HTTP server response size.", lbs).
+			With(lbs).Observe(float64(size))
+	}
+}
+
 func MeasureHTTPClientRequest(req *http.Request, path string) func(int) {
 	lbs := prometheus.Labels{
 		"method": req.Method,
@@ -121,6 +166,37 @@ func GetCounter(name, help string, labels prometheus.Labels) *prometheus.Counter
 	return h
 }
 
+func GetGauge(name, help string, labels prometheus.Labels) *prometheus.GaugeVec {
+	if appName != "" {
+		labels["app"] = appName
+	}
+
+	if appVersion != "" {
+		labels["app_v"] = appVersion
+	}
+
+	k := metricKey(name, labels)
+
+	mux.RLock()
+	g, ok := gauges[k]
+	if !ok {
+		mux.RUnlock()
+		mux.Lock()
+		defer mux.Unlock()
+
+		g = promauto.NewGaugeVec(prometheus.GaugeOpts{
+			Name: name,
+			Help: help,
+		}, labelKeys(labels))
+
+		gauges[k] = g
+	} else {
+		mux.RUnlock()
+	}
+
+	return g
+}
+
 func GetHistogram(name, help string, labels prometheus.Labels) *prometheus.HistogramVec {
 	k := metricKey(name, labels)
 
@@ -144,6 +220,22 @@ func GetHistogram(name, help string, labels prometheus.Labels) *prometheus.Histo
 	return h
 }
 
+// RegisterRuntimeCollectors replaces the default Go collector with the one exporting GC and scheduler
+// metrics from runtime/metrics and makes sure the process collector is registered.
+func RegisterRuntimeCollectors() {
+	runtimeCollectorsOnce.Do(func() {
+		prometheus.Unregister(collectors.NewGoCollector())
+		prometheus.MustRegister(collectors.NewGoCollector(
+			collectors.WithGoCollectorRuntimeMetrics(collectors.MetricsGC, collectors.MetricsScheduler),
+		))
+
+		err := prometheus.Register(collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+		if are := (prometheus.AlreadyRegisteredError{}); err != nil && !errors.As(err, &are) {
+			panic(err)
+		}
+	})
+}
+
 func labelKeys(labels prometheus.Labels) []string {
 	res := make([]string, 0, len(labels))
 	for k := range labels {
@@ -0,0 +1,48 @@
+package runner
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+type BuildInfo struct {
+	AppName     string `json:"app"`
+	AppVersion  string `json:"app_v"`
+	GoVersion   string `json:"go_version"`
+	Module      string `json:"module,omitempty"`
+	VCSRevision string `json:"vcs_revision,omitempty"`
+	VCSTime     string `json:"vcs_time,omitempty"`
+	VCSModified bool   `json:"vcs_modified,omitempty"`
+	OS          string `json:"os"`
+	Arch        string `json:"arch"`
+}
+
+// BuildInfo returns the app build information, partially taken from debug.ReadBuildInfo.
+func (rt *Runtime[CT]) BuildInfo() BuildInfo {
+	res := BuildInfo{
+		AppName:    rt.AppName,
+		AppVersion: rt.AppVersion,
+		GoVersion:  runtime.Version(),
+		OS:         runtime.GOOS,
+		Arch:       runtime.GOARCH,
+	}
+
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return res
+	}
+
+	res.Module = bi.Main.Path + " " + bi.Main.Version
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			res.VCSRevision = s.Value
+		case "vcs.time":
+			res.VCSTime = s.Value
+		case "vcs.modified":
+			res.VCSModified = s.Value == "true"
+		}
+	}
+
+	return res
+}
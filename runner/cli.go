@@ -0,0 +1,160 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/ashep/go-app/health"
+	"github.com/ashep/go-app/httpserver"
+	"gopkg.in/yaml.v3"
+)
+
+// defaultHealthcheckURL points to the health endpoint of a server listening on the default address.
+const defaultHealthcheckURL = "http://" + httpserver.DefaultAddr + health.URLPath
+
+// SetArgs sets command line arguments used to select a built-in or registered command. Defaults to os.Args[1:].
+func (r *Runner[RT, CT]) SetArgs(args []string) *Runner[RT, CT] {
+	r.args = args
+	return r
+}
+
+// SetOutput sets the writer built-in commands print to. Defaults to os.Stdout.
+func (r *Runner[RT, CT]) SetOutput(w io.Writer) *Runner[RT, CT] {
+	r.out = w
+	return r
+}
+
+// SetHealthcheckURL sets the URL probed by the healthcheck command. Defaults to the health endpoint of a server
+// listening on httpserver.DefaultAddr.
+//
+// The value can be overridden by APP_HEALTHCHECK_URL or <APPNAME>_HEALTHCHECK_URL env vars.
+func (r *Runner[RT, CT]) SetHealthcheckURL(u string) *Runner[RT, CT] {
+	r.healthcheckURL = u
+	return r
+}
+
+// runCLI runs a built-in command if it is requested by command line arguments.
+func (r *Runner[RT, CT]) runCLI() (bool, error) {
+	args := r.cliArgs()
+	if len(args) == 0 {
+		return false, nil
+	}
+
+	switch {
+	case args[0] == "version" || args[0] == "--version":
+		return true, r.cmdVersion()
+	case args[0] == "config" && len(args) > 1 && args[1] == "validate":
+		return true, r.cmdConfigValidate()
+	case args[0] == "config" && len(args) > 1 && args[1] == "dump":
+		return true, r.cmdConfigDump()
+	case args[0] == "healthcheck":
+		return true, r.cmdHealthcheck()
+	case args[0] == "help" || args[0] == "--help" || args[0] == "-h":
+		r.printUsage()
+		return true, nil
+	}
+
+	return false, nil
+}
+
+func (r *Runner[RT, CT]) cliArgs() []string {
+	if r.args == nil && len(os.Args) > 1 {
+		return os.Args[1:]
+	}
+
+	return r.args
+}
+
+func (r *Runner[RT, CT]) cmdVersion() error {
+	bi := r.rt.BuildInfo()
+
+	_, _ = fmt.Fprintf(r.out, "%s %s\n", bi.AppName, bi.AppVersion)
+	_, _ = fmt.Fprintf(r.out, "go: %s %s/%s\n", bi.GoVersion, bi.OS, bi.Arch)
+
+	if bi.Module != "" {
+		_, _ = fmt.Fprintf(r.out, "module: %s\n", bi.Module)
+	}
+
+	if bi.VCSRevision != "" {
+		_, _ = fmt.Fprintf(r.out, "revision: %s %s", bi.VCSRevision, bi.VCSTime)
+		if bi.VCSModified {
+			_, _ = fmt.Fprint(r.out, " (modified)")
+		}
+		_, _ = fmt.Fprintln(r.out)
+	}
+
+	return nil
+}
+
+func (r *Runner[RT, CT]) cmdConfigValidate() error {
+	err := errors.Join(r.errs...)
+	if err == nil {
+		err = validateConfig(r.rt.Cfg)
+	}
+
+	if err != nil {
+		_, _ = fmt.Fprintf(r.out, "config is invalid:\n%s\n", err)
+		return err
+	}
+
+	_, _ = fmt.Fprintln(r.out, "config is valid")
+
+	return nil
+}
+
+func (r *Runner[RT, CT]) cmdConfigDump() error {
+	if err := errors.Join(r.errs...); err != nil {
+		_, _ = fmt.Fprintf(r.out, "config is invalid:\n%s\n", err)
+		return err
+	}
+
+	b, err := yaml.Marshal(redactConfig(r.rt.Cfg))
+	if err != nil {
+		return fmt.Errorf("marshal config: %w", err)
+	}
+
+	_, err = r.out.Write(b)
+
+	return err
+}
+
+func (r *Runner[RT, CT]) cmdHealthcheck() error {
+	u := r.healthcheckURL
+	for _, prefix := range []string{"APP", r.rt.AppName2} {
+		if v := os.Getenv(prefix + "_HEALTHCHECK_URL"); v != "" {
+			u = v
+		}
+	}
+
+	ctx, ctxC := context.WithTimeout(context.Background(), time.Second*5)
+	defer ctxC()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		_, _ = fmt.Fprintf(r.out, "unhealthy: %s\n", err)
+		return fmt.Errorf("send request: %w", err)
+	}
+
+	defer func() {
+		_ = res.Body.Close()
+	}()
+
+	if res.StatusCode != http.StatusOK {
+		_, _ = fmt.Fprintf(r.out, "unhealthy: %s\n", res.Status)
+		return fmt.Errorf("unexpected response status: %s", res.Status)
+	}
+
+	_, _ = fmt.Fprintln(r.out, "healthy")
+
+	return nil
+}
@@ -0,0 +1,185 @@
+package runner_test
+
+import (
+	"bytes"
+	"net/http"
+	"runtime"
+	"testing"
+
+	"github.com/ashep/go-app/health"
+	"github.com/ashep/go-app/runner"
+	"github.com/ashep/go-app/testhttpserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type cliCfg struct {
+	Name     string `yaml:"name"`
+	Password string `yaml:"password" secret:"true"`
+	DB       struct {
+		DSN string `json:"dsn" secret:"true"`
+	} `yaml:"db"`
+}
+
+func TestRunner_Run_CLI(main *testing.T) {
+	newRunner := func(t *testing.T, out *bytes.Buffer, args ...string) *runner.Runner[func(*runner.Runtime[cliCfg]) error, cliCfg] {
+		return runner.New(func(rt *runner.Runtime[cliCfg]) error {
+			t.Fatal("run must not be called")
+			return nil
+		}).
+			SetAppName("foo").
+			SetAppVersion("1.2.3").
+			SetArgs(args).
+			SetOutput(out)
+	}
+
+	main.Run("Version", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		require.NoError(t, newRunner(t, out, "version").Run())
+		assert.Contains(t, out.String(), "foo 1.2.3\ngo: "+runtime.Version()+" "+runtime.GOOS+"/"+runtime.GOARCH+"\n")
+	})
+
+	main.Run("ConfigValidateOk", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		require.NoError(t, newRunner(t, out, "config", "validate").Run())
+		assert.Equal(t, "config is valid\n", out.String())
+	})
+
+	main.Run("ConfigValidateFailed", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		err := runner.New(func(rt *runner.Runtime[envCfg]) error {
+			t.Fatal("run must not be called")
+			return nil
+		}).SetArgs([]string{"config", "validate"}).SetOutput(out).Run()
+
+		require.EqualError(t, err, "config validation: Int: must be positive")
+		assert.Equal(t, "config is invalid:\nconfig validation: Int: must be positive\n", out.String())
+	})
+
+	main.Run("ConfigDump", func(t *testing.T) {
+		t.Setenv("FOO_PASSWORD", "aPassword")
+		t.Setenv("FOO_DB_DSN", "aDSN")
+
+		out := &bytes.Buffer{}
+		r := newRunner(t, out, "config", "dump")
+		r.SetConfig(cliCfg{Name: "aName"}).LoadEnvConfig()
+
+		require.NoError(t, r.Run())
+		assert.Equal(t, "db:\n    dsn: '***'\nname: aName\npassword: '***'\n", out.String())
+	})
+
+	main.Run("HealthcheckOk", func(t *testing.T) {
+		s := testhttpserver.New(t)
+		health.RegisterServer(s)
+		s.Run()
+
+		t.Setenv("APP_HEALTHCHECK_URL", s.URL(health.URLPath))
+
+		out := &bytes.Buffer{}
+		require.NoError(t, newRunner(t, out, "healthcheck").Run())
+		assert.Equal(t, "healthy\n", out.String())
+	})
+
+	main.Run("HealthcheckFailed", func(t *testing.T) {
+		s := testhttpserver.New(t)
+		s.HandleFunc(health.URLPath, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		})
+		s.Run()
+
+		out := &bytes.Buffer{}
+		err := newRunner(t, out, "healthcheck").SetHealthcheckURL(s.URL(health.URLPath)).Run()
+		require.EqualError(t, err, "unexpected response status: 503 Service Unavailable")
+		assert.Equal(t, "unhealthy: 503 Service Unavailable\n", out.String())
+	})
+}
+
+func TestRunner_AddCommand(main *testing.T) {
+	newRunner := func(t *testing.T, out *bytes.Buffer, args ...string) *runner.Runner[func(*runner.Runtime[runCfg]) error, runCfg] {
+		return runner.New[func(*runner.Runtime[runCfg]) error](nil).
+			SetAppName("foo").
+			SetArgs(args).
+			SetOutput(out).
+			AddCommand("serve", "Run the server", func(rt *runner.Runtime[runCfg]) error {
+				_, _ = out.WriteString("serve " + rt.Command)
+				return nil
+			}).
+			AddCommand("worker", "Run the worker", func(rt *runner.Runtime[runCfg]) error {
+				_, _ = out.WriteString("worker " + rt.Command)
+				return nil
+			})
+	}
+
+	main.Run("SelectedByArg", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		require.NoError(t, newRunner(t, out, "worker").Run())
+		assert.Equal(t, "worker worker", out.String())
+	})
+
+	main.Run("SelectedByEnv", func(t *testing.T) {
+		t.Setenv("FOO_COMMAND", "serve")
+
+		out := &bytes.Buffer{}
+		require.NoError(t, newRunner(t, out).Run())
+		assert.Equal(t, "serve serve", out.String())
+	})
+
+	main.Run("Help", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		require.NoError(t, newRunner(t, out, "--help").Run())
+		assert.Contains(t, out.String(), "Usage: foo <command>\n\nCommands:\n")
+		assert.Contains(t, out.String(), "  serve            Run the server\n  worker           Run the worker\n")
+		assert.Contains(t, out.String(), "  config validate  Validate the config\n")
+	})
+
+	main.Run("NoCommand", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		require.ErrorIs(t, newRunner(t, out).Run(), runner.ErrNoCommand)
+		assert.Contains(t, out.String(), "Usage: foo <command>")
+	})
+
+	main.Run("RunContext", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		require.NoError(t, newRunner(t, out, "worker").RunContext(t.Context()))
+		assert.Equal(t, "worker worker", out.String())
+	})
+
+	main.Run("RunContextWithDefault", func(t *testing.T) {
+		newDefaultRunner := func(out *bytes.Buffer) *runner.Runner[func(*runner.Runtime[runCfg]) error, runCfg] {
+			return runner.New(func(rt *runner.Runtime[runCfg]) error {
+				_, _ = out.WriteString("default")
+				return nil
+			}).
+				SetAppName("foo").
+				SetArgs([]string{}).
+				AddCommand("worker", "Run the worker", func(rt *runner.Runtime[runCfg]) error {
+					_, _ = out.WriteString("worker " + rt.Command)
+					return nil
+				})
+		}
+
+		out := &bytes.Buffer{}
+		require.NoError(t, newDefaultRunner(out).RunContext(t.Context()))
+		assert.Equal(t, "default", out.String())
+
+		t.Setenv("APP_COMMAND", "worker")
+
+		out.Reset()
+		require.NoError(t, newDefaultRunner(out).RunContext(t.Context()))
+		assert.Equal(t, "worker worker", out.String())
+	})
+
+	main.Run("RunContextNoCommand", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		require.ErrorIs(t, newRunner(t, out).RunContext(t.Context()), runner.ErrNoCommand)
+
+		err := runner.New[func(*runner.Runtime[runCfg]) error](nil).RunContext(t.Context())
+		require.ErrorIs(t, err, runner.ErrNoCommand)
+	})
+
+	main.Run("UnknownCommand", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		require.EqualError(t, newRunner(t, out, "migrate").Run(), "unknown command: migrate")
+		assert.Contains(t, out.String(), "unknown command: migrate\n\nUsage: foo <command>")
+	})
+}
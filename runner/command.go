@@ -0,0 +1,91 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+var ErrNoCommand = errors.New("no command specified")
+
+type command[RT any] struct {
+	name        string
+	description string
+	run         RT
+}
+
+// AddCommand registers a named run function. The command is selected by the first command line argument or
+// by APP_COMMAND or <APPNAME>_COMMAND env vars. If no command is selected, the run function passed to New is
+// used, which may be nil for apps consisting of commands only.
+//
+// All the commands share the runner config and logging setup. Built-in commands take precedence over
+// the registered ones.
+func (r *Runner[RT, CT]) AddCommand(name, description string, run RT) *Runner[RT, CT] {
+	r.commands = append(r.commands, command[RT]{name: name, description: description, run: run})
+	return r
+}
+
+// selectCommand replaces the run function with the one of the command requested by args or env vars.
+func (r *Runner[RT, CT]) selectCommand() error {
+	r.cmdSelected = true
+
+	if len(r.commands) == 0 {
+		return nil
+	}
+
+	var name string
+	if args := r.cliArgs(); len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name = args[0]
+	}
+
+	if name == "" {
+		for _, prefix := range []string{"APP", r.rt.AppName2} {
+			if v := os.Getenv(prefix + "_COMMAND"); v != "" {
+				name = v
+			}
+		}
+	}
+
+	if name == "" {
+		if r.noRun() {
+			r.printUsage()
+			return ErrNoCommand
+		}
+		return nil
+	}
+
+	for _, c := range r.commands {
+		if c.name == name {
+			r.run = c.run
+			r.rt.Command = name
+			return nil
+		}
+	}
+
+	_, _ = fmt.Fprintf(r.out, "unknown command: %s\n\n", name)
+	r.printUsage()
+
+	return fmt.Errorf("unknown command: %s", name)
+}
+
+// noRun reports whether there is no run function to run.
+func (r *Runner[RT, CT]) noRun() bool {
+	return (func(*Runtime[CT]) error)(r.run) == nil
+}
+
+func (r *Runner[RT, CT]) printUsage() {
+	_, _ = fmt.Fprintf(r.out, "Usage: %s <command>\n\nCommands:\n", r.rt.AppName)
+
+	w := tabwriter.NewWriter(r.out, 0, 0, 2, ' ', 0)
+	for _, c := range r.commands {
+		_, _ = fmt.Fprintf(w, "  %s\t%s\n", c.name, c.description)
+	}
+	_, _ = fmt.Fprintf(w, "  %s\t%s\n", "version", "Print version information")
+	_, _ = fmt.Fprintf(w, "  %s\t%s\n", "config validate", "Validate the config")
+	_, _ = fmt.Fprintf(w, "  %s\t%s\n", "config dump", "Print the config with secrets redacted")
+	_, _ = fmt.Fprintf(w, "  %s\t%s\n", "healthcheck", "Probe the running app health endpoint")
+	_, _ = fmt.Fprintf(w, "  %s\t%s\n", "help", "Print this help")
+	_ = w.Flush()
+}
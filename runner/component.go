@@ -0,0 +1,177 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+const defaultComponentStopTimeout = time.Second * 5
+
+// Component is a long-living part of an application, e.g. an HTTP server, a queue consumer or a DB pool.
+//
+// Start must not block longer than needed to bring the component up. Stop receives a context carrying
+// the component's stop deadline.
+type Component interface {
+	Start(ctx context.Context) error
+	Stop(ctx context.Context) error
+}
+
+// Named may be implemented by a Component to give it a human-readable name used in logs and errors.
+type Named interface {
+	Name() string
+}
+
+type ComponentError struct {
+	Component string
+	Op        string
+	Err       error
+}
+
+func (e *ComponentError) Error() string {
+	return fmt.Sprintf("%s %s: %s", e.Op, e.Component, e.Err)
+}
+
+func (e *ComponentError) Unwrap() error {
+	return e.Err
+}
+
+// Register starts a component and puts it under the runtime control.
+//
+// Components are started synchronously in the order they are registered, so a component must be registered
+// after the components it depends on. Registered components are stopped in reverse order once the run
+// function returns and the runtime context is done. If a component fails to start, the runtime context gets
+// cancelled, so all the other components are stopped, and the error is returned from Runner.RunContext.
+func (rt *Runtime[CT]) Register(c Component) error {
+	name := componentName(c)
+
+	rt.compMux.Lock()
+	stopping := rt.stopping
+	rt.compMux.Unlock()
+
+	if stopping {
+		return &ComponentError{Component: name, Op: "start", Err: errors.New("runtime is stopping")}
+	}
+
+	rt.Log.Debug().Str("component", name).Msg("starting component")
+
+	// The lock must not be held while calling the component, since Start may block for long
+	if err := c.Start(rt.Ctx); err != nil {
+		err = &ComponentError{Component: name, Op: "start", Err: err}
+		rt.compMux.Lock()
+		rt.errs = append(rt.errs, err)
+		rt.compMux.Unlock()
+		rt.cancel()
+		return err
+	}
+
+	rt.compMux.Lock()
+	if rt.stopping {
+		rt.compMux.Unlock()
+
+		// The runtime started stopping components while this one was starting, so it won't be stopped there
+		ctx, ctxC := context.WithTimeout(context.Background(), defaultComponentStopTimeout)
+		defer ctxC()
+		if err := c.Stop(ctx); err != nil {
+			rt.Log.Error().Err(err).Str("component", name).Msg("component stop failed")
+		}
+
+		return &ComponentError{Component: name, Op: "start", Err: errors.New("runtime is stopping")}
+	}
+	rt.components = append(rt.components, c)
+	rt.storeComponentNames()
+	rt.compMux.Unlock()
+
+	rt.Log.Debug().Str("component", name).Msg("component started")
+
+	return nil
+}
+
+// stopComponents stops all registered components in reverse order.
+func (rt *Runtime[CT]) stopComponents(timeout time.Duration) error {
+	rt.compMux.Lock()
+	rt.stopping = true
+	components := slices.Clone(rt.components)
+	rt.compMux.Unlock()
+
+	var errs []error
+
+	for i := len(components) - 1; i >= 0; i-- {
+		c := components[i]
+		name := componentName(c)
+
+		rt.Log.Debug().Str("component", name).Msg("stopping component")
+
+		ctx, ctxC := context.WithTimeout(context.Background(), timeout)
+		err := c.Stop(ctx)
+		ctxC()
+
+		rt.compMux.Lock()
+		rt.components = rt.components[:i]
+		rt.storeComponentNames()
+		rt.compMux.Unlock()
+
+		if err != nil {
+			rt.Log.Error().Err(err).Str("component", name).Msg("component stop failed")
+			errs = append(errs, &ComponentError{Component: name, Op: "stop", Err: err})
+			continue
+		}
+
+		rt.Log.Debug().Str("component", name).Msg("component stopped")
+	}
+
+	return errors.Join(errs...)
+}
+
+// componentErrors returns component start errors except those already wrapped by err.
+func (rt *Runtime[CT]) componentErrors(err error) error {
+	rt.compMux.Lock()
+	defer rt.compMux.Unlock()
+
+	var errs []error
+	for _, e := range rt.errs {
+		if !errors.Is(err, e) {
+			errs = append(errs, e)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (rt *Runtime[CT]) hasComponents() bool {
+	rt.compMux.Lock()
+	defer rt.compMux.Unlock()
+
+	return len(rt.components) != 0
+}
+
+// runningComponents returns names of components which are started and not stopped yet.
+//
+// It doesn't take the components lock, so it is safe to call on forced exit paths.
+func (rt *Runtime[CT]) runningComponents() []string {
+	if names := rt.compNames.Load(); names != nil {
+		return *names
+	}
+
+	return []string{}
+}
+
+// storeComponentNames publishes names of the registered components. Must be called with compMux held.
+func (rt *Runtime[CT]) storeComponentNames() {
+	names := make([]string, 0, len(rt.components))
+	for _, c := range rt.components {
+		names = append(names, componentName(c))
+	}
+
+	rt.compNames.Store(&names)
+}
+
+func componentName(c Component) string {
+	if n, ok := c.(Named); ok {
+		return n.Name()
+	}
+
+	return fmt.Sprintf("%T", c)
+}
@@ -0,0 +1,62 @@
+package runner
+
+import (
+	"errors"
+	"strings"
+
+	apperrors "github.com/ashep/go-app/errors"
+	"github.com/kelseyhightower/envconfig"
+)
+
+// ConfigError describes a failure to load or validate the app config.
+type ConfigError struct {
+	Source string // config file path, env vars prefix or "validation"
+	Field  string // config field, if known
+	Err    error
+}
+
+func (e *ConfigError) Error() string {
+	s := "config"
+
+	if e.Source != "" {
+		s += " " + e.Source
+	}
+
+	msg := e.Err.Error()
+	if e.Field != "" && !strings.HasPrefix(msg, e.Field+":") {
+		s += ": " + e.Field
+	}
+
+	return s + ": " + msg
+}
+
+func (e *ConfigError) Unwrap() error {
+	return e.Err
+}
+
+func newFileConfigError(path string, err error) *ConfigError {
+	return &ConfigError{Source: path, Err: err}
+}
+
+func newEnvConfigError(prefix string, err error) *ConfigError {
+	ce := &ConfigError{Source: prefix + "_", Err: err}
+
+	var pErr *envconfig.ParseError
+	if errors.As(err, &pErr) {
+		ce.Field = pErr.FieldName
+		ce.Err = pErr.Err
+	}
+
+	return ce
+}
+
+func newValidationConfigError(err error) *ConfigError {
+	ce := &ConfigError{Source: "validation", Err: err}
+
+	var iaErr apperrors.InvalidArgError
+	if errors.As(err, &iaErr) {
+		ce.Field = iaErr.Arg
+	}
+
+	return ce
+}
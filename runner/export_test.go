@@ -0,0 +1,9 @@
+package runner
+
+func SetExit(f func(int)) func() {
+	prev := exit
+	exit = f
+	return func() {
+		exit = prev
+	}
+}
@@ -0,0 +1,73 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+const defaultLogFlushTimeout = time.Second * 5
+
+// LogFlusher may be implemented by a log writer which buffers records.
+type LogFlusher interface {
+	Flush(ctx context.Context) error
+}
+
+// SetLogFlushTimeout sets the time log writers are given to flush and close when the app stops.
+func (r *Runner[RT, CT]) SetLogFlushTimeout(d time.Duration) *Runner[RT, CT] {
+	r.logFlushTimeout = d
+	return r
+}
+
+// flushLogWriters flushes log writers implementing LogFlusher or having the Sync method
+// and, if closeWriters is true, closes those implementing io.Closer except the standard output streams.
+func (r *Runner[RT, CT]) flushLogWriters(closeWriters bool) {
+	ctx, ctxC := context.WithTimeout(context.Background(), r.logFlushTimeout)
+	defer ctxC()
+
+	done := make(chan error, 1)
+	go func() {
+		var errs []error
+
+		for _, w := range r.logWriters {
+			switch fw := w.w.(type) {
+			case LogFlusher:
+				errs = append(errs, fw.Flush(ctx))
+			case interface{ Sync() error }:
+				if !isStdStream(w.w) {
+					errs = append(errs, fw.Sync())
+				}
+			}
+
+			if c, ok := w.w.(io.Closer); ok && closeWriters && !isStdStream(w.w) {
+				errs = append(errs, c.Close())
+			}
+		}
+
+		done <- errors.Join(errs...)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	if err != nil {
+		// The logger cannot be relied on at this point
+		_, _ = os.Stderr.WriteString("ERROR: flushing log writers: " + err.Error() + "\n")
+	}
+}
+
+func isStdStream(w io.Writer) bool {
+	if cw, ok := w.(zerolog.ConsoleWriter); ok {
+		w = cw.Out
+	}
+
+	return w == os.Stdout || w == os.Stderr
+}
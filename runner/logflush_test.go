@@ -0,0 +1,83 @@
+package runner_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ashep/go-app/runner"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type bufferedLogWriter struct {
+	mux     sync.Mutex
+	buf     bytes.Buffer
+	flushed bytes.Buffer
+	closed  bool
+}
+
+func (w *bufferedLogWriter) Write(b []byte) (int, error) {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+
+	return w.buf.Write(b)
+}
+
+func (w *bufferedLogWriter) Flush(ctx context.Context) error {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+
+	_, err := w.buf.WriteTo(&w.flushed)
+
+	return err
+}
+
+func (w *bufferedLogWriter) Close() error {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+
+	w.closed = true
+
+	return nil
+}
+
+type stuckLogWriter struct{}
+
+func (w *stuckLogWriter) Write(b []byte) (int, error) {
+	return len(b), nil
+}
+
+func (w *stuckLogWriter) Flush(ctx context.Context) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestRunner_RunContext_FlushLogWriters(main *testing.T) {
+	main.Run("Ok", func(t *testing.T) {
+		w := &bufferedLogWriter{}
+
+		err := runner.New(func(rt *runner.Runtime[runCfg]) error {
+			return errors.New("anError")
+		}).AddLogWriter(w).RunContext(t.Context())
+		require.EqualError(t, err, "anError")
+
+		assert.Empty(t, w.buf.String())
+		assert.Contains(t, w.flushed.String(), `"message":"app run failed"`)
+		assert.True(t, w.closed)
+	})
+
+	main.Run("Timeout", func(t *testing.T) {
+		start := time.Now()
+
+		err := runner.New(func(rt *runner.Runtime[runCfg]) error {
+			return nil
+		}).AddLogWriter(&stuckLogWriter{}).SetLogFlushTimeout(time.Millisecond * 50).RunContext(t.Context())
+		require.NoError(t, err)
+
+		assert.Less(t, time.Since(start), time.Second)
+	})
+}
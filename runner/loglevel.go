@@ -0,0 +1,257 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	LogLevelURLPath = "/debug/loglevel"
+
+	defaultLogLevelTTL = time.Minute * 10
+)
+
+type LogWriterOption func(*logWriter)
+
+// WithMinLevel makes a log writer receive only records of the given level or above.
+func WithMinLevel(l zerolog.Level) LogWriterOption {
+	return func(w *logWriter) {
+		w.minLevel = l
+	}
+}
+
+type logWriter struct {
+	w        io.Writer
+	minLevel zerolog.Level
+}
+
+func (w logWriter) levelWriter() zerolog.LevelWriter {
+	lw, ok := w.w.(zerolog.LevelWriter)
+	if !ok {
+		lw = zerolog.LevelWriterAdapter{Writer: w.w}
+	}
+
+	if w.minLevel == zerolog.TraceLevel {
+		return lw
+	}
+
+	return &zerolog.FilteredLevelWriter{Writer: lw, Level: w.minLevel}
+}
+
+// logLevel holds the log level of a runtime which can be changed at runtime.
+//
+// Records below the level are discarded by the hook. While the runtime is running, zerolog's global level is kept
+// at the most verbose level of all running runtimes, so records below it are not even encoded.
+type logLevel struct {
+	mux   sync.Mutex
+	base  zerolog.Level
+	cur   atomic.Int32
+	timer *time.Timer
+}
+
+func (l *logLevel) get() zerolog.Level {
+	return zerolog.Level(l.cur.Load())
+}
+
+// init sets both the base and the current level.
+func (l *logLevel) init(lvl zerolog.Level) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	l.base = lvl
+	l.cur.Store(int32(lvl))
+}
+
+// set changes the current level and reverts it to the base one after ttl.
+func (l *logLevel) set(lvl zerolog.Level, ttl time.Duration) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	l.stopTimer()
+	l.cur.Store(int32(lvl))
+	globalLevels.update()
+
+	if ttl > 0 {
+		l.timer = time.AfterFunc(ttl, func() {
+			l.mux.Lock()
+			defer l.mux.Unlock()
+
+			l.cur.Store(int32(l.base))
+			l.timer = nil
+			globalLevels.update()
+		})
+	}
+}
+
+// activate makes the level affect zerolog's global level.
+func (l *logLevel) activate() {
+	globalLevels.add(l)
+}
+
+// deactivate cancels a pending revert to the base level and stops affecting zerolog's global level.
+func (l *logLevel) deactivate() {
+	l.mux.Lock()
+	l.stopTimer()
+	l.mux.Unlock()
+
+	globalLevels.remove(l)
+}
+
+func (l *logLevel) stopTimer() {
+	if l.timer != nil {
+		l.timer.Stop()
+		l.timer = nil
+	}
+}
+
+// Run discards records below the level. It must be the first hook of the logger.
+func (l *logLevel) Run(e *zerolog.Event, lvl zerolog.Level, _ string) {
+	if lvl != zerolog.NoLevel && lvl < l.get() {
+		e.Discard()
+	}
+}
+
+// globalLevels keeps zerolog's global level at the most verbose level of running runtimes and restores
+// the original global level once none are running.
+var globalLevels = &logLevelSet{levels: make(map[*logLevel]struct{})} //nolint:gochecknoglobals // zerolog's global level is global too
+
+type logLevelSet struct {
+	mux    sync.Mutex
+	levels map[*logLevel]struct{}
+	prev   zerolog.Level
+}
+
+func (s *logLevelSet) add(l *logLevel) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	if len(s.levels) == 0 {
+		s.prev = zerolog.GlobalLevel()
+	}
+	s.levels[l] = struct{}{}
+
+	s.apply()
+}
+
+func (s *logLevelSet) remove(l *logLevel) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	if _, ok := s.levels[l]; !ok {
+		return
+	}
+	delete(s.levels, l)
+
+	if len(s.levels) == 0 {
+		zerolog.SetGlobalLevel(s.prev)
+		return
+	}
+
+	s.apply()
+}
+
+func (s *logLevelSet) update() {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	if len(s.levels) > 0 {
+		s.apply()
+	}
+}
+
+func (s *logLevelSet) apply() {
+	lvl := zerolog.Disabled
+	for l := range s.levels {
+		lvl = min(lvl, l.get())
+	}
+
+	zerolog.SetGlobalLevel(lvl)
+}
+
+// LogLevel returns the current log level.
+//
+// Runtime.Log is created at the trace level so that the level can be lowered at runtime, therefore its GetLevel
+// always reports trace. Use LogLevel instead.
+func (rt *Runtime[CT]) LogLevel() zerolog.Level {
+	return rt.logLevel.get()
+}
+
+// SetLogLevel changes the log level of the runtime. If ttl is not zero, the level is reverted to the one
+// configured at startup after ttl elapses.
+func (rt *Runtime[CT]) SetLogLevel(l zerolog.Level, ttl time.Duration) {
+	rt.logLevel.set(l, ttl)
+	rt.Log.WithLevel(zerolog.NoLevel).Str("log_level", l.String()).Dur("ttl", ttl).Msg("log level changed")
+}
+
+// RegisterLogLevelServer serves the current log level on GET and changes it on PUT or POST
+// with "level" and optional "ttl" query args, e.g. ?level=debug&ttl=5m. The TTL defaults to 10 minutes.
+func (rt *Runtime[CT]) RegisterLogLevelServer(srv httpServer) {
+	srv.Handle(LogLevelURLPath, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut, http.MethodPost:
+			lvl, err := parseLogLevel(r.URL.Query().Get("level"))
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			ttl := defaultLogLevelTTL
+			if v := r.URL.Query().Get("ttl"); v != "" {
+				if ttl, err = time.ParseDuration(v); err != nil || ttl <= 0 {
+					http.Error(w, "invalid ttl", http.StatusBadRequest)
+					return
+				}
+			}
+
+			rt.SetLogLevel(lvl, ttl)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]string{"level": rt.LogLevel().String()})
+	}))
+}
+
+// envLogLevel returns the log level configured by *_DEBUG and *_LOG_LEVEL env vars.
+func (r *Runner[RT, CT]) envLogLevel() (zerolog.Level, error) {
+	res := zerolog.InfoLevel
+
+	for _, prefix := range []string{"APP", r.rt.AppName2} {
+		if dbg := strings.ToLower(os.Getenv(prefix + "_DEBUG")); dbg == "true" || dbg == "1" {
+			res = zerolog.DebugLevel
+		}
+	}
+
+	for _, prefix := range []string{"APP", r.rt.AppName2} {
+		if v := os.Getenv(prefix + "_LOG_LEVEL"); v != "" {
+			l, err := parseLogLevel(v)
+			if err != nil {
+				return res, &ConfigError{Source: prefix + "_", Field: "LOG_LEVEL", Err: err}
+			}
+			res = l
+		}
+	}
+
+	return res, nil
+}
+
+func parseLogLevel(s string) (zerolog.Level, error) {
+	switch strings.ToLower(s) {
+	case "trace", "debug", "info", "warn", "error":
+		return zerolog.ParseLevel(strings.ToLower(s))
+	}
+
+	return zerolog.NoLevel, fmt.Errorf("invalid log level %q", s)
+}
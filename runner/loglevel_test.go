@@ -0,0 +1,170 @@
+package runner_test
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ashep/go-app/runner"
+	"github.com/ashep/go-app/testhttpserver"
+	"github.com/ashep/go-app/testlogger"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunner_LogLevel(main *testing.T) {
+	main.Run("EnvAndWriterLevels", func(t *testing.T) {
+		t.Setenv("APP_DEBUG", "1")
+		t.Setenv("FOO_LOG_LEVEL", "trace")
+
+		lAll := testlogger.New(t)
+		lWarn := testlogger.New(t)
+
+		err := runner.New(func(rt *runner.Runtime[runCfg]) error {
+			rt.Log.Trace().Msg("trace message")
+			rt.Log.Info().Msg("info message")
+			rt.Log.Warn().Msg("warn message")
+			return nil
+		}).
+			SetAppName("foo").
+			AddLogWriter(lAll.Logger()).
+			AddLogWriter(lWarn.Logger(), runner.WithMinLevel(zerolog.WarnLevel)).
+			RunContext(t.Context())
+		require.NoError(t, err)
+
+		lAll.AssertContains(`"message":"trace message"`)
+		lAll.AssertContains(`"message":"info message"`)
+		lAll.AssertContains(`"message":"warn message"`)
+
+		assert.NotContains(t, lWarn.Content(), `"message":"trace message"`)
+		assert.NotContains(t, lWarn.Content(), `"message":"info message"`)
+		lWarn.AssertContains(`"message":"warn message"`)
+	})
+
+	main.Run("InvalidEnv", func(t *testing.T) {
+		t.Setenv("APP_LOG_LEVEL", "verbose")
+
+		err := runner.New(func(rt *runner.Runtime[runCfg]) error {
+			return nil
+		}).RunContext(t.Context())
+
+		assert.EqualError(t, err, `config APP_: LOG_LEVEL: invalid log level "verbose"`)
+	})
+
+	main.Run("BelowLevelNotEnabled", func(t *testing.T) {
+		err := runner.New(func(rt *runner.Runtime[runCfg]) error {
+			assert.Equal(t, zerolog.InfoLevel, rt.LogLevel())
+			assert.False(t, rt.Log.Debug().Enabled())
+			assert.True(t, rt.Log.Info().Enabled())
+
+			rt.SetLogLevel(zerolog.DebugLevel, 0)
+			assert.True(t, rt.Log.Debug().Enabled())
+			assert.False(t, rt.Log.Trace().Enabled())
+
+			return nil
+		}).RunContext(t.Context())
+		require.NoError(t, err)
+	})
+
+	main.Run("GlobalLevelRestored", func(t *testing.T) {
+		prev := zerolog.GlobalLevel()
+
+		err := runner.New(func(rt *runner.Runtime[runCfg]) error {
+			assert.Equal(t, zerolog.InfoLevel, zerolog.GlobalLevel())
+			return nil
+		}).RunContext(t.Context())
+		require.NoError(t, err)
+
+		assert.Equal(t, prev, zerolog.GlobalLevel())
+	})
+
+	main.Run("ConcurrentRunners", func(t *testing.T) {
+		lInfo := testlogger.New(t)
+		lDebug := testlogger.New(t)
+		ready := &sync.WaitGroup{}
+		ready.Add(2)
+		logged := make(chan struct{})
+
+		run := func(l *testlogger.Logger, lvl zerolog.Level) <-chan error {
+			done := make(chan error, 1)
+			go func() {
+				done <- runner.New(func(rt *runner.Runtime[runCfg]) error {
+					rt.SetLogLevel(lvl, 0)
+					ready.Done()
+					ready.Wait()
+					rt.Log.Debug().Msg("debug message")
+					<-logged
+					return nil
+				}).AddLogWriter(l.Logger()).RunContext(t.Context())
+			}()
+			return done
+		}
+
+		doneInfo := run(lInfo, zerolog.InfoLevel)
+		doneDebug := run(lDebug, zerolog.DebugLevel)
+
+		ready.Wait()
+		assert.Equal(t, zerolog.DebugLevel, zerolog.GlobalLevel())
+		assert.Eventually(t, func() bool {
+			return strings.Contains(lDebug.Content(), `"message":"debug message"`)
+		}, time.Second, time.Millisecond*10)
+		close(logged)
+
+		require.NoError(t, <-doneInfo)
+		require.NoError(t, <-doneDebug)
+		assert.NotContains(t, lInfo.Content(), "debug message")
+	})
+
+	main.Run("RuntimeChange", func(t *testing.T) {
+		l := testlogger.New(t)
+		s := testhttpserver.New(t)
+		var rtP atomic.Pointer[runner.Runtime[runCfg]]
+
+		go func() {
+			_ = runner.New(func(rt *runner.Runtime[runCfg]) error {
+				rt.RegisterLogLevelServer(s)
+				rtP.Store(rt)
+				<-rt.Ctx.Done()
+				return nil
+			}).AddLogWriter(l.Logger()).RunContext(t.Context())
+		}()
+
+		require.Eventually(t, func() bool { return rtP.Load() != nil }, time.Second, time.Millisecond*10)
+		s.Run()
+		rt := rtP.Load()
+
+		rt.Log.Debug().Msg("debug message 1")
+
+		req, err := http.NewRequest(http.MethodPut, s.URL(runner.LogLevelURLPath+"?level=debug&ttl=100ms"), nil)
+		require.NoError(t, err)
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		b, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.JSONEq(t, `{"level":"debug"}`, string(b))
+
+		rt.Log.Debug().Msg("debug message 2")
+
+		assert.Eventually(t, func() bool {
+			return rt.LogLevel() == zerolog.InfoLevel
+		}, time.Second, time.Millisecond*10)
+
+		rt.Log.Debug().Msg("debug message 3")
+
+		assert.NotContains(t, l.Content(), "debug message 1")
+		l.AssertContains("debug message 2")
+		assert.NotContains(t, l.Content(), "debug message 3")
+
+		res, err = http.Post(s.URL(runner.LogLevelURLPath+"?level=verbose"), "", strings.NewReader(""))
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	})
+}
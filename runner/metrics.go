@@ -0,0 +1,54 @@
+package runner
+
+import (
+	"time"
+
+	"github.com/ashep/go-app/prommetrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// EnableMetrics makes the runner export Go runtime and process metrics, app build info, start time
+// and config reloads count through prommetrics.
+func (r *Runner[RT, CT]) EnableMetrics() *Runner[RT, CT] {
+	r.metrics = true
+	return r
+}
+
+func (r *Runner[RT, CT]) registerMetrics() {
+	prommetrics.RegisterRuntimeCollectors()
+
+	bi := r.rt.BuildInfo()
+
+	lbs := prometheus.Labels{
+		"app":          r.rt.AppName,
+		"app_v":        r.rt.AppVersion,
+		"go_version":   bi.GoVersion,
+		"vcs_revision": bi.VCSRevision,
+	}
+	prommetrics.GetGauge("app_build_info", "App build information.", lbs).With(lbs).Set(1)
+
+	lbs = prometheus.Labels{
+		"app":   r.rt.AppName,
+		"app_v": r.rt.AppVersion,
+	}
+	prommetrics.GetGauge("app_start_time_seconds", "App start time since unix epoch in seconds.", lbs).
+		With(lbs).Set(float64(time.Now().Unix()))
+}
+
+func (r *Runner[RT, CT]) countConfigReload(err error) {
+	if !r.metrics {
+		return
+	}
+
+	lbs := prometheus.Labels{
+		"app":    r.rt.AppName,
+		"app_v":  r.rt.AppVersion,
+		"result": "success",
+	}
+
+	if err != nil {
+		lbs["result"] = "failure"
+	}
+
+	prommetrics.GetCounter("app_config_reloads_total", "Config reloads count.", lbs).With(lbs).Inc()
+}
@@ -0,0 +1,88 @@
+package runner_test
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ashep/go-app/prommetrics"
+	"github.com/ashep/go-app/runner"
+	"github.com/ashep/go-app/testhttpserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunner_EnableMetrics(main *testing.T) {
+	main.Run("Ok", func(t *testing.T) {
+		p := filepath.Join(t.TempDir(), "config.yaml")
+		require.NoError(t, os.WriteFile(p, []byte("name: foo"), 0o600))
+
+		s := testhttpserver.New(t)
+		prommetrics.RegisterServer("metrics-app", "1.2.3", s)
+		s.Run()
+
+		started := make(chan struct{})
+		go func() {
+			_ = runner.New(func(rt *runner.Runtime[reloadCfg]) error {
+				close(started)
+				<-rt.Ctx.Done()
+				return nil
+			}).
+				SetAppName("metrics-app").
+				SetAppVersion("1.2.3").
+				LoadConfigFile(p).
+				EnableConfigReload(time.Millisecond * 10).
+				EnableMetrics().
+				RunContext(t.Context())
+		}()
+
+		getMetrics := func() string {
+			res, err := http.Get(s.URL(prommetrics.URLPath))
+			require.NoError(t, err)
+			defer func() {
+				_ = res.Body.Close()
+			}()
+			b, err := io.ReadAll(res.Body)
+			require.NoError(t, err)
+			return string(b)
+		}
+
+		<-started
+		before := getMetrics()
+		require.Contains(t, before, "app_start_time_seconds")
+
+		require.NoError(t, os.WriteFile(p, []byte("name: foobar"), 0o600))
+
+		const reloadSeries = `app_config_reloads_total{app="metrics-app",app_v="1.2.3",result="success"}`
+		require.Eventually(t, func() bool {
+			return metricValue(t, getMetrics(), reloadSeries) == metricValue(t, before, reloadSeries)+1
+		}, time.Second, time.Millisecond*10)
+
+		m := getMetrics()
+		assert.Contains(t, m, `app_build_info{app="metrics-app",app_v="1.2.3",go_version="`+runtime.Version()+`"`)
+		assert.Contains(t, m, `app_start_time_seconds{app="metrics-app",app_v="1.2.3"}`)
+		assert.Contains(t, m, "go_sched_goroutines_goroutines")
+		assert.Contains(t, m, "process_start_time_seconds")
+	})
+}
+
+// metricValue returns the value of the series from the metrics exposition or zero if there is no such series.
+func metricValue(t *testing.T, metrics, series string) float64 {
+	t.Helper()
+
+	for _, line := range strings.Split(metrics, "\n") {
+		if v, ok := strings.CutPrefix(line, series+" "); ok {
+			f, err := strconv.ParseFloat(v, 64)
+			require.NoError(t, err)
+			return f
+		}
+	}
+
+	return 0
+}
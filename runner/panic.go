@@ -0,0 +1,61 @@
+package runner
+
+import (
+	"fmt"
+	"runtime/debug"
+
+	"github.com/rs/zerolog"
+)
+
+// PanicError describes a panic recovered in the run function, a goroutine started by the runtime or a scheduled job.
+// It is logged and passed to the crash hook, after which the process exits with code 2, so Runner.RunContext
+// doesn't get to return it.
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// SetCrashHook sets a function called after a panic is recovered and logged, right before the process exits.
+// It can be used to report the crash to an external service.
+func (r *Runner[RT, CT]) SetCrashHook(h func(err *PanicError)) *Runner[RT, CT] {
+	r.crashHook = h
+	return r
+}
+
+// protect calls fn and turns its panic into an error, calling the crash handler.
+func (rt *Runtime[CT]) protect(fn func() error) error {
+	return recoverPanic(fn, rt.crash)
+}
+
+// recoverPanic calls fn and turns its panic into an error, passing it to onPanic first.
+func recoverPanic(fn func() error, onPanic func(err *PanicError)) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			pErr := &PanicError{Value: v, Stack: debug.Stack()}
+			onPanic(pErr)
+			err = pErr
+		}
+	}()
+
+	return fn()
+}
+
+// crash logs a recovered panic, flushes log writers, calls the crash hook and exits the process.
+func (r *Runner[RT, CT]) crash(err *PanicError) {
+	r.rt.Log.WithLevel(zerolog.FatalLevel).
+		Str("panic", fmt.Sprint(err.Value)).
+		Str("stack", string(err.Stack)).
+		Msg("app panicked")
+
+	r.flushLogWriters(false)
+
+	if r.crashHook != nil {
+		r.crashHook(err)
+	}
+
+	exit(2)
+}
@@ -0,0 +1,104 @@
+package runner_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ashep/go-app/runner"
+	"github.com/ashep/go-app/testlogger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunner_SetCrashHook(main *testing.T) {
+	main.Run("RunPanic", func(t *testing.T) {
+		exitCode := make(chan int, 1)
+		defer runner.SetExit(func(code int) { exitCode <- code })()
+
+		l := testlogger.New(t)
+
+		var crashErr *runner.PanicError
+		err := runner.New(func(rt *runner.Runtime[runCfg]) error {
+			panic("aPanic")
+		}).
+			AddLogWriter(l.Logger()).
+			SetCrashHook(func(err *runner.PanicError) { crashErr = err }).
+			RunContext(t.Context())
+
+		var pErr *runner.PanicError
+		require.ErrorAs(t, err, &pErr)
+		assert.Equal(t, "aPanic", pErr.Value)
+		assert.Contains(t, string(pErr.Stack), "panic_test.go")
+		assert.Same(t, pErr, crashErr)
+		assert.Equal(t, 2, <-exitCode)
+		l.AssertContains(`"level":"fatal","message":"app panicked","panic":"aPanic","stack":"goroutine`)
+	})
+
+	main.Run("GoPanic", func(t *testing.T) {
+		exitCode := make(chan int, 1)
+		defer runner.SetExit(func(code int) { exitCode <- code })()
+
+		var crashErr *runner.PanicError
+		err := runner.New(func(rt *runner.Runtime[runCfg]) error {
+			rt.Go("aName", func(ctx context.Context) error {
+				panic("aPanic")
+			})
+			return nil
+		}).
+			SetCrashHook(func(err *runner.PanicError) { crashErr = err }).
+			RunContext(t.Context())
+
+		var pErr *runner.PanicError
+		require.ErrorAs(t, err, &pErr)
+		assert.Same(t, pErr, crashErr)
+		assert.Equal(t, 2, <-exitCode)
+	})
+
+	main.Run("GoPanicRestarted", func(t *testing.T) {
+		defer runner.SetExit(func(code int) { t.Errorf("unexpected exit with code %d", code) })()
+
+		l := testlogger.New(t)
+		ctx, ctxC := context.WithTimeout(t.Context(), time.Millisecond*100)
+		defer ctxC()
+
+		var runs atomic.Int32
+		err := runner.New(func(rt *runner.Runtime[runCfg]) error {
+			rt.Go("aName", func(ctx context.Context) error {
+				if runs.Add(1) < 3 {
+					panic("aPanic")
+				}
+				<-ctx.Done()
+				return nil
+			}, runner.WithRestartPolicy(runner.RestartOnFailure), runner.WithRestartBackoff(time.Millisecond, time.Second))
+			return nil
+		}).
+			SetCrashHook(func(err *runner.PanicError) { t.Errorf("unexpected crash: %v", err) }).
+			AddLogWriter(l.Logger()).
+			RunContext(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, int32(3), runs.Load())
+		l.AssertContains(`"goroutine":"aName","level":"error","message":"goroutine panicked","panic":"aPanic"`)
+		l.AssertContains(`"error":"panic: aPanic","goroutine":"aName","level":"warn","message":"goroutine restarting","restarts":2`)
+	})
+
+	main.Run("GoPanicTooManyRestarts", func(t *testing.T) {
+		exitCode := make(chan int, 1)
+		defer runner.SetExit(func(code int) { exitCode <- code })()
+
+		err := runner.New(func(rt *runner.Runtime[runCfg]) error {
+			rt.Go("aName", func(ctx context.Context) error {
+				panic("aPanic")
+			}, runner.WithRestartPolicy(runner.RestartOnFailure), runner.WithMaxRestarts(1, time.Minute),
+				runner.WithRestartBackoff(time.Millisecond, time.Second))
+			return nil
+		}).RunContext(t.Context())
+
+		var pErr *runner.PanicError
+		require.ErrorAs(t, err, &pErr)
+		require.ErrorIs(t, err, runner.ErrTooManyRestarts)
+		assert.Equal(t, 2, <-exitCode)
+	})
+}
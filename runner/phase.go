@@ -0,0 +1,73 @@
+package runner
+
+type Phase int32
+
+const (
+	PhaseStarting Phase = iota
+	PhaseReady
+	PhaseDraining
+	PhaseStopped
+)
+
+func (p Phase) String() string {
+	switch p {
+	case PhaseStarting:
+		return "starting"
+	case PhaseReady:
+		return "ready"
+	case PhaseDraining:
+		return "draining"
+	case PhaseStopped:
+		return "stopped"
+	}
+
+	return "unknown"
+}
+
+// Phase returns the current app lifecycle phase.
+func (rt *Runtime[CT]) Phase() Phase {
+	return Phase(rt.phase.Load())
+}
+
+// IsReady reports whether the app is ready to serve traffic. It makes the Runtime suitable for
+// health.WithReadiness.
+func (rt *Runtime[CT]) IsReady() bool {
+	return rt.Phase() == PhaseReady
+}
+
+// MarkReady tells the runner that the app initialization is done.
+//
+// If the run function returns right after registering components, starting goroutines or scheduling jobs,
+// the app is marked ready automatically.
+func (rt *Runtime[CT]) MarkReady() {
+	rt.setPhase(PhaseReady)
+}
+
+// onPhase subscribes f to phase changes.
+func (rt *Runtime[CT]) onPhase(f func(Phase)) {
+	rt.phaseMux.Lock()
+	defer rt.phaseMux.Unlock()
+
+	rt.phaseSubs = append(rt.phaseSubs, f)
+}
+
+// setPhase moves the app to a later lifecycle phase. Moving backwards is ignored.
+func (rt *Runtime[CT]) setPhase(p Phase) {
+	rt.phaseMux.Lock()
+
+	if cur := Phase(rt.phase.Load()); p <= cur {
+		rt.phaseMux.Unlock()
+		return
+	}
+
+	rt.phase.Store(int32(p))
+	subs := rt.phaseSubs
+
+	rt.phaseMux.Unlock()
+
+	rt.Log.Debug().Str("phase", p.String()).Msg("app phase changed")
+
+	for _, f := range subs {
+		f(p)
+	}
+}
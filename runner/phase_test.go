@@ -0,0 +1,79 @@
+package runner_test
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ashep/go-app/health"
+	"github.com/ashep/go-app/runner"
+	"github.com/ashep/go-app/testhttpserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuntime_Phase(main *testing.T) {
+	main.Run("MarkReady", func(t *testing.T) {
+		s := testhttpserver.New(t)
+		ctx, ctxC := context.WithCancel(t.Context())
+
+		getStatus := func() int {
+			res, err := http.Get(s.URL(health.URLPath))
+			require.NoError(t, err)
+			_ = res.Body.Close()
+			return res.StatusCode
+		}
+
+		var rt *runner.Runtime[runCfg]
+		err := runner.New(func(r *runner.Runtime[runCfg]) error {
+			rt = r
+			health.RegisterServer(s, health.WithReadiness(rt))
+			s.Run()
+
+			assert.Equal(t, runner.PhaseStarting, rt.Phase())
+			assert.Equal(t, http.StatusServiceUnavailable, getStatus())
+
+			rt.MarkReady()
+			assert.Equal(t, runner.PhaseReady, rt.Phase())
+			assert.Equal(t, http.StatusOK, getStatus())
+
+			ctxC()
+			assert.Eventually(t, func() bool {
+				return rt.Phase() == runner.PhaseDraining
+			}, time.Second, time.Millisecond)
+			assert.Equal(t, http.StatusServiceUnavailable, getStatus())
+
+			// Phases never go backwards
+			rt.MarkReady()
+			assert.Equal(t, runner.PhaseDraining, rt.Phase())
+
+			return nil
+		}).RunContext(ctx)
+		require.NoError(t, err)
+
+		assert.Equal(t, runner.PhaseStopped, rt.Phase())
+	})
+
+	main.Run("ReadyAfterComponentsRegistered", func(t *testing.T) {
+		ctx, ctxC := context.WithCancel(t.Context())
+		var rtP atomic.Pointer[runner.Runtime[runCfg]]
+
+		go func() {
+			assert.Eventually(t, func() bool {
+				rt := rtP.Load()
+				return rt != nil && rt.IsReady()
+			}, time.Second, time.Millisecond)
+			ctxC()
+		}()
+
+		err := runner.New(func(rt *runner.Runtime[runCfg]) error {
+			require.NoError(t, rt.Register(&componentMock{name: "c", rec: &componentRecorder{}}))
+			assert.False(t, rt.IsReady())
+			rtP.Store(rt)
+			return nil
+		}).RunContext(ctx)
+		require.NoError(t, err)
+	})
+}
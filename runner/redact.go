@@ -0,0 +1,103 @@
+package runner
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+const redactedValue = "***"
+
+var textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]() //nolint:gochecknoglobals // constant
+
+// redactConfig converts a config into a tree of maps, slices and scalars suitable for marshalling
+// with values of fields tagged `secret:"true"` replaced by "***".
+func redactConfig(v any) any {
+	return redactValue(reflect.ValueOf(v))
+}
+
+func redactValue(v reflect.Value) any {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	if !v.IsValid() {
+		return nil
+	}
+
+	if tm, ok := v.Interface().(encoding.TextMarshaler); ok {
+		if b, err := tm.MarshalText(); err == nil {
+			return string(b)
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		res := make(map[string]any)
+
+		t := v.Type()
+		for i := range t.NumField() {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+
+			name := fieldName(f)
+			if name == "-" {
+				continue
+			}
+
+			if f.Tag.Get("secret") == "true" {
+				if !v.Field(i).IsZero() {
+					res[name] = redactedValue
+				} else {
+					res[name] = ""
+				}
+				continue
+			}
+
+			res[name] = redactValue(v.Field(i))
+		}
+
+		return res
+	case reflect.Map:
+		res := make(map[string]any, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			res[fmtMapKey(iter.Key())] = redactValue(iter.Value())
+		}
+		return res
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+		res := make([]any, v.Len())
+		for i := range v.Len() {
+			res[i] = redactValue(v.Index(i))
+		}
+		return res
+	case reflect.Invalid, reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		return nil
+	default:
+		return v.Interface()
+	}
+}
+
+// fieldName returns the name of a struct field as it appears in config files.
+func fieldName(f reflect.StructField) string {
+	for _, tag := range []string{"yaml", "json"} {
+		if n, _, _ := strings.Cut(f.Tag.Get(tag), ","); n != "" {
+			return n
+		}
+	}
+
+	return strings.ToLower(f.Name)
+}
+
+func fmtMapKey(v reflect.Value) string {
+	return fmt.Sprint(v.Interface())
+}
@@ -0,0 +1,147 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"slices"
+	"syscall"
+	"time"
+)
+
+// EnableConfigReload makes the runner reload the config on SIGHUP.
+//
+// The config is rebuilt by replaying SetConfig, LoadConfigFile and LoadEnvConfig calls in the order
+// they were made, validated and swapped only if valid. If watchPeriod is not zero, config files are also
+// polled for changes with the given period.
+func (r *Runner[RT, CT]) EnableConfigReload(watchPeriod time.Duration) *Runner[RT, CT] {
+	r.cfgReload = true
+	r.cfgWatchPeriod = watchPeriod
+	return r
+}
+
+// Config returns the current config.
+//
+// Unlike Cfg, which always points to the config loaded at startup, it reflects hot reloads.
+func (rt *Runtime[CT]) Config() *CT {
+	return rt.cfg.Load()
+}
+
+// OnConfigChange subscribes f to config changes made by successful hot reloads.
+func (rt *Runtime[CT]) OnConfigChange(f func(old, new *CT)) {
+	rt.cfgMux.Lock()
+	defer rt.cfgMux.Unlock()
+
+	rt.cfgSubs = append(rt.cfgSubs, f)
+}
+
+// watchConfig starts reloading config on SIGHUP and, optionally, on config files change.
+func (r *Runner[RT, CT]) watchConfig(ctx context.Context) {
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGHUP)
+
+	// Taken before returning, so changes made right after the app starts are not missed
+	states := r.configFileStates()
+
+	go func() {
+		defer signal.Stop(sig)
+
+		var tick <-chan time.Time
+		if r.cfgWatchPeriod > 0 {
+			t := time.NewTicker(r.cfgWatchPeriod)
+			defer t.Stop()
+			tick = t.C
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-sig:
+				r.rt.Log.Info().Msg("reloading config on SIGHUP")
+				states = r.configFileStates()
+			case <-tick:
+				newStates := r.configFileStates()
+				if newStates == states {
+					continue
+				}
+				states = newStates
+				r.rt.Log.Info().Msg("reloading config on file change")
+			}
+
+			err := r.reloadConfig()
+			if err != nil {
+				r.rt.Log.Error().Err(err).Msg("config reload failed")
+			}
+
+			r.countConfigReload(err)
+		}
+	}()
+}
+
+func (r *Runner[RT, CT]) reloadConfig() error {
+	cfg := new(CT)
+	sources := defaultConfigSources(cfg)
+
+	var errs []error
+	for _, l := range r.rt.cfgLoaders {
+		if err := l.apply(cfg, sources); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+
+	if err := validateConfig(cfg); err != nil {
+		return err
+	}
+
+	r.rt.cfgMux.Lock()
+	old := r.rt.cfg.Swap(cfg)
+	r.rt.cfgSources = sources
+	subs := r.rt.cfgSubs
+	r.rt.cfgMux.Unlock()
+
+	r.rt.Log.Info().Msg("config reloaded")
+
+	for _, f := range subs {
+		f(old, cfg)
+	}
+
+	return nil
+}
+
+// configFileStates returns a string describing modification state of all config files.
+func (r *Runner[RT, CT]) configFileStates() string {
+	paths := slices.Clone(r.cfgPaths)
+	for _, prefix := range []string{"APP", r.rt.AppName2} {
+		if p := os.Getenv(prefix + "_CONFIG_PATH"); p != "" {
+			paths = append(paths, p)
+		}
+	}
+
+	res := ""
+	for _, p := range paths {
+		if fi, err := os.Stat(p); err == nil {
+			res += fmt.Sprintf("%s:%d:%d;", p, fi.ModTime().UnixNano(), fi.Size())
+		} else {
+			res += p + ":-;"
+		}
+	}
+
+	return res
+}
+
+func validateConfig[CT any](cfg *CT) error {
+	if v, ok := any(cfg).(Validatable); ok {
+		if err := v.Validate(); err != nil {
+			return newValidationConfigError(err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,104 @@
+package runner_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/ashep/go-app/runner"
+	"github.com/ashep/go-app/testlogger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type reloadCfg struct {
+	Name string `yaml:"name"`
+}
+
+func (c *reloadCfg) Validate() error {
+	if c.Name == "" {
+		return errors.New("name is required")
+	}
+	return nil
+}
+
+func TestRunner_EnableConfigReload(main *testing.T) {
+	writeCfg := func(t *testing.T, p, content string) {
+		require.NoError(t, os.WriteFile(p, []byte(content), 0o600))
+	}
+
+	main.Run("SIGHUP", func(t *testing.T) {
+		p := filepath.Join(t.TempDir(), "config.yaml")
+		writeCfg(t, p, "name: foo")
+
+		l := testlogger.New(t)
+		started := make(chan struct{})
+		changes := make(chan [2]string, 1)
+
+		go func() {
+			_ = runner.New(func(rt *runner.Runtime[reloadCfg]) error {
+				rt.OnConfigChange(func(old, new *reloadCfg) {
+					changes <- [2]string{old.Name, new.Name}
+				})
+				close(started)
+				<-rt.Ctx.Done()
+				return nil
+			}).
+				LoadConfigFile(p).
+				EnableConfigReload(0).
+				AddLogWriter(l.Logger()).
+				RunContext(t.Context())
+		}()
+
+		<-started
+
+		// Invalid config must be rejected
+		writeCfg(t, p, "name: ''")
+		require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGHUP))
+		assert.Eventually(t, func() bool {
+			return strings.Contains(l.Content(), `"message":"config reload failed"`)
+		}, time.Second, time.Millisecond*10)
+		l.AssertContains(`"error":"config validation: name is required"`)
+
+		writeCfg(t, p, "name: bar")
+		require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGHUP))
+		select {
+		case ch := <-changes:
+			assert.Equal(t, [2]string{"foo", "bar"}, ch)
+		case <-time.After(time.Second):
+			t.Fatal("config change was not notified")
+		}
+	})
+
+	main.Run("FileWatch", func(t *testing.T) {
+		p := filepath.Join(t.TempDir(), "config.yaml")
+		writeCfg(t, p, "name: foo")
+
+		var rtP atomic.Pointer[runner.Runtime[reloadCfg]]
+		go func() {
+			_ = runner.New(func(rt *runner.Runtime[reloadCfg]) error {
+				rtP.Store(rt)
+				<-rt.Ctx.Done()
+				return nil
+			}).
+				LoadConfigFile(p).
+				EnableConfigReload(time.Millisecond * 10).
+				RunContext(t.Context())
+		}()
+
+		require.Eventually(t, func() bool { return rtP.Load() != nil }, time.Second, time.Millisecond*10)
+		rt := rtP.Load()
+		assert.Equal(t, "foo", rt.Config().Name)
+
+		writeCfg(t, p, "name: foobar")
+		assert.Eventually(t, func() bool {
+			return rt.Config().Name == "foobar"
+		}, time.Second, time.Millisecond*10)
+		assert.Equal(t, "foo", rt.Cfg.Name)
+	})
+}
@@ -8,12 +8,16 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
+	"time"
 
 	"github.com/ashep/go-app/cfgloader"
 	"github.com/ashep/go-app/httplogwriter"
+	"github.com/ashep/go-app/systemd"
 	"github.com/rs/zerolog"
 )
 
@@ -33,14 +37,62 @@ type Runtime[CT any] struct {
 	AppName    string
 	AppName2   string
 	AppVersion string
+	Command    string
 	Cfg        *CT
 	Log        zerolog.Logger
+
+	logLevel logLevel
+
+	cfg        atomic.Pointer[CT]
+	cfgLoaders []configLoader[CT]
+	cfgSources map[string]string
+	cfgMux     sync.Mutex
+	cfgSubs    []func(old, new *CT)
+
+	cancel     context.CancelFunc
+	compMux    sync.Mutex
+	components []Component
+	compNames  atomic.Pointer[[]string]
+	errs       []error
+	stopping   bool
+
+	jobsWG   sync.WaitGroup
+	jobCount atomic.Int32
+	goWG     sync.WaitGroup
+	goCount  atomic.Int32
+	crash    func(err *PanicError)
+
+	phase     atomic.Int32
+	phaseMux  sync.Mutex
+	phaseSubs []func(Phase)
 }
 
 type Runner[RT func(*Runtime[CT]) error, CT any] struct {
-	run        RT
-	logWriters []io.Writer
-	rt         *Runtime[CT]
+	run             RT
+	commands        []command[RT]
+	cmdSelected     bool
+	logWriters      []logWriter
+	rt              *Runtime[CT]
+	compStopTimeout time.Duration
+	crashHook       func(err *PanicError)
+	logFlushTimeout time.Duration
+	shutdownTimeout time.Duration
+	drainDelay      time.Duration
+	logOnce         sync.Once
+	errs            []error
+	args            []string
+	out             io.Writer
+	healthcheckURL  string
+	tracing         bool
+	metrics         bool
+	systemd         bool
+
+	logSampler              *logSampler
+	logSamplingReportPeriod time.Duration
+
+	cfgPaths       []string
+	cfgReload      bool
+	cfgWatchPeriod time.Duration
 }
 
 func New[RT func(*Runtime[CT]) error, CT any](run RT) *Runner[RT, CT] {
@@ -48,16 +100,17 @@ func New[RT func(*Runtime[CT]) error, CT any](run RT) *Runner[RT, CT] {
 	mux.Lock()
 	defer mux.Unlock()
 
+	var initErr error
+
 	if appName == "" {
 		appName = os.Getenv("APP_NAME")
 	}
 	if appName == "" {
-		wd, err := os.Getwd()
-		if err != nil {
-			fmt.Println("Unable to determine current working directory")
-			os.Exit(1)
+		if wd, err := os.Getwd(); err != nil {
+			initErr = fmt.Errorf("determine current working directory: %w", err)
+		} else {
+			appName = filepath.Base(wd)
 		}
-		appName = filepath.Base(wd)
 	}
 
 	if appVer == "" {
@@ -73,12 +126,28 @@ func New[RT func(*Runtime[CT]) error, CT any](run RT) *Runner[RT, CT] {
 		AppVersion: appVer,
 		Cfg:        new(CT),
 	}
+	rt.cfg.Store(rt.Cfg)
+	rt.cfgSources = defaultConfigSources(rt.Cfg)
+
+	r := &Runner[RT, CT]{
+		run:             run,
+		rt:              rt,
+		logWriters:      make([]logWriter, 0),
+		compStopTimeout: defaultComponentStopTimeout,
+		out:             os.Stdout,
+		healthcheckURL:  defaultHealthcheckURL,
+		logFlushTimeout: defaultLogFlushTimeout,
+
+		logSamplingReportPeriod: defaultLogSamplingReportPeriod,
+	}
 
-	return &Runner[RT, CT]{
-		run:        run,
-		rt:         rt,
-		logWriters: make([]io.Writer, 0),
+	rt.crash = r.crash
+
+	if initErr != nil {
+		r.errs = append(r.errs, initErr)
 	}
+
+	return r
 }
 
 func (r *Runner[RT, CT]) SetAppName(name string) *Runner[RT, CT] {
@@ -93,25 +162,62 @@ func (r *Runner[RT, CT]) SetAppVersion(ver string) *Runner[RT, CT] {
 }
 
 func (r *Runner[RT, CT]) SetConfig(cfg CT) *Runner[RT, CT] {
+	def := cfg // cfg itself is modified by subsequent loaders
+
+	l := configLoader[CT]{
+		source: configSourceDefault,
+		load: func(out *CT) error {
+			*out = def
+			return nil
+		},
+	}
+	r.rt.cfgLoaders = append(r.rt.cfgLoaders, l)
+
+	updateConfigSources(r.rt.cfgSources, configLeaves(r.rt.Cfg), configLeaves(&cfg), l)
+
 	r.rt.Cfg = &cfg
+	r.rt.cfg.Store(r.rt.Cfg)
+
+	return r
+}
+
+// SetComponentStopTimeout sets the time each registered component is given to stop.
+func (r *Runner[RT, CT]) SetComponentStopTimeout(d time.Duration) *Runner[RT, CT] {
+	r.compStopTimeout = d
 	return r
 }
 
 func (r *Runner[RT, CT]) LoadConfigFile(path string) *Runner[RT, CT] {
-	err := cfgloader.LoadFromPath(path, r.rt.Cfg, nil)
-	if err != nil && !errors.Is(err, os.ErrNotExist) {
-		fmt.Printf("Error loading config from %s: %s\n", path, err)
-		os.Exit(1)
-	}
+	r.cfgPaths = append(r.cfgPaths, path)
+
+	r.addConfigLoader(configLoader[CT]{
+		source: configSourceFile,
+		arg:    func() string { return path },
+		load: func(out *CT) error {
+			err := cfgloader.LoadFromPath(path, out, nil)
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				return newFileConfigError(path, err)
+			}
+			return nil
+		},
+	})
 
 	// Load config from additional file
 	for _, prefix := range []string{"APP", r.rt.AppName2} {
-		if cfgPath := os.Getenv(prefix + "_CONFIG_PATH"); cfgPath != "" {
-			if err := cfgloader.LoadFromPath(cfgPath, r.rt.Cfg, nil); err != nil {
-				fmt.Printf("Error loading config from %s: %s\n", cfgPath, err)
-				os.Exit(1)
-			}
-		}
+		cfgPath := func() string { return os.Getenv(prefix + "_CONFIG_PATH") }
+
+		r.addConfigLoader(configLoader[CT]{
+			source: configSourceFile,
+			arg:    cfgPath,
+			load: func(out *CT) error {
+				if p := cfgPath(); p != "" {
+					if err := cfgloader.LoadFromPath(p, out, nil); err != nil {
+						return newFileConfigError(p, err)
+					}
+				}
+				return nil
+			},
+		})
 	}
 
 	return r
@@ -119,21 +225,44 @@ func (r *Runner[RT, CT]) LoadConfigFile(path string) *Runner[RT, CT] {
 
 func (r *Runner[RT, CT]) LoadEnvConfig() *Runner[RT, CT] {
 	for _, prefix := range []string{"APP", r.rt.AppName2} {
-		if err := cfgloader.LoadFromEnv(prefix, r.rt.Cfg); err != nil {
-			fmt.Printf("Error loading config from %s_ env vars failed", prefix)
-			os.Exit(1)
-		}
+		r.addConfigLoader(configLoader[CT]{
+			source: configSourceEnv,
+			arg:    func() string { return prefix },
+			load: func(out *CT) error {
+				if err := cfgloader.LoadFromEnv(prefix, out); err != nil {
+					return newEnvConfigError(prefix, err)
+				}
+				return nil
+			},
+		})
 	}
 
 	return r
 }
 
-func (r *Runner[RT, CT]) AddLogWriter(w io.Writer) *Runner[RT, CT] {
-	r.logWriters = append(r.logWriters, w)
+// addConfigLoader applies a config loader and remembers it to be replayed on config reload.
+//
+// Loading errors are accumulated and returned from RunContext.
+func (r *Runner[RT, CT]) addConfigLoader(l configLoader[CT]) {
+	r.rt.cfgLoaders = append(r.rt.cfgLoaders, l)
+
+	if err := l.apply(r.rt.Cfg, r.rt.cfgSources); err != nil {
+		r.errs = append(r.errs, err)
+	}
+}
+
+func (r *Runner[RT, CT]) AddLogWriter(w io.Writer, opts ...LogWriterOption) *Runner[RT, CT] {
+	lw := logWriter{w: w, minLevel: zerolog.TraceLevel}
+	for _, opt := range opts {
+		opt(&lw)
+	}
+
+	r.logWriters = append(r.logWriters, lw)
+
 	return r
 }
 
-func (r *Runner[RT, CT]) AddConsoleLogWriter() *Runner[RT, CT] {
+func (r *Runner[RT, CT]) AddConsoleLogWriter(opts ...LogWriterOption) *Runner[RT, CT] {
 	var w io.Writer
 
 	if isTerminal() {
@@ -142,10 +271,10 @@ func (r *Runner[RT, CT]) AddConsoleLogWriter() *Runner[RT, CT] {
 		w = os.Stderr
 	}
 
-	return r.AddLogWriter(w)
+	return r.AddLogWriter(w, opts...)
 }
 
-func (r *Runner[RT, CT]) AddHTTPLogWriter() *Runner[RT, CT] {
+func (r *Runner[RT, CT]) AddHTTPLogWriter(opts ...LogWriterOption) *Runner[RT, CT] {
 	var (
 		w   *httplogwriter.Writer
 		err error
@@ -168,30 +297,110 @@ func (r *Runner[RT, CT]) AddHTTPLogWriter() *Runner[RT, CT] {
 		return r
 	}
 
-	return r.AddLogWriter(w)
+	return r.AddLogWriter(w, opts...)
 }
 
 func (r *Runner[RT, CT]) RunContext(ctx context.Context) error {
+	// Run selects the command before, but RunContext may be called directly
+	if !r.cmdSelected {
+		if err := r.selectCommand(); err != nil {
+			return err
+		}
+	}
+
+	if r.noRun() {
+		return ErrNoCommand
+	}
+
+	ctx, ctxC := context.WithCancel(ctx)
+	defer ctxC()
+
 	r.rt.Ctx = ctx
+	r.rt.cancel = ctxC
+
+	r.initLogger()
+	defer r.flushLogWriters(true)
+	r.rt.logLevel.activate()
+	defer r.rt.logLevel.deactivate()
+
+	if r.logSampler != nil {
+		stopReporter := r.startLogSamplingReporter()
+		defer stopReporter()
+	}
 
-	logLevel := zerolog.InfoLevel
-	if dbg := strings.ToLower(os.Getenv("APP_DEBUG")); dbg == "true" || dbg == "1" {
-		logLevel = zerolog.DebugLevel
+	if err := errors.Join(slices.Concat(r.errs, r.shutdownEnvErrors())...); err != nil {
+		r.logConfigError(err, "config load failed")
+		return err
+	}
+
+	if err := validateConfig(r.rt.Cfg); err != nil {
+		r.logConfigError(err, "config validation failed")
+		return err
+	}
+
+	if r.rt.LogLevel() <= zerolog.DebugLevel {
+		if b, err := r.rt.ConfigSnapshot().JSON(); err == nil {
+			r.rt.Log.Debug().RawJSON("config", b).Msg("effective config")
+		}
+	}
+
+	if r.metrics {
+		r.registerMetrics()
 	}
-	if dbg := strings.ToLower(os.Getenv(r.rt.AppName2 + "_DEBUG")); dbg == "true" || dbg == "1" {
-		logLevel = zerolog.DebugLevel
+
+	if r.cfgReload {
+		r.watchConfig(ctx)
 	}
-	r.rt.Log = zerolog.New(zerolog.MultiLevelWriter(r.logWriters...)).Level(logLevel).
-		With().Str("app", r.rt.AppName).Str("app_v", r.rt.AppVersion).Logger()
 
-	if appCfgT, ok := any(r.rt.Cfg).(Validatable); ok {
-		if err := appCfgT.Validate(); err != nil {
-			r.rt.Log.Error().Err(err).Msg("config validation failed")
+	if r.tracing {
+		stopTracing, err := r.startTracing(ctx)
+		if err != nil {
+			r.rt.Log.Error().Err(err).Msg("tracing start failed")
 			return err
 		}
+		defer stopTracing()
+	}
+
+	if r.systemd {
+		stopWatchdog := r.startSystemdWatchdog()
+		defer stopWatchdog()
+
+		r.rt.onPhase(func(p Phase) {
+			switch p {
+			case PhaseReady:
+				r.systemdNotify(systemd.StateReady)
+			case PhaseDraining:
+				r.systemdNotify(systemd.StateStopping)
+			}
+		})
 	}
 
-	if err := r.run(r.rt); err != nil && !errors.Is(err, context.Canceled) {
+	defer r.rt.setPhase(PhaseStopped)
+
+	go func() {
+		<-ctx.Done()
+		r.rt.setPhase(PhaseDraining)
+	}()
+
+	runErr := r.rt.protect(func() error { return r.run(r.rt) })
+	if errors.Is(runErr, context.Canceled) {
+		runErr = nil
+	}
+
+	// The run function is allowed to return right after registering components, starting goroutines
+	// or scheduling jobs
+	if runErr == nil && (r.rt.hasComponents() || r.rt.goCount.Load() > 0 || r.rt.jobCount.Load() > 0) {
+		r.rt.MarkReady()
+		<-ctx.Done()
+	}
+
+	ctxC()
+	r.rt.setPhase(PhaseDraining)
+	r.rt.jobsWG.Wait()
+	r.rt.goWG.Wait()
+
+	err := errors.Join(runErr, r.rt.componentErrors(runErr), r.rt.stopComponents(r.compStopTimeout))
+	if err != nil {
 		r.rt.Log.Error().Err(err).Msg("app run failed")
 		return err
 	}
@@ -199,18 +408,105 @@ func (r *Runner[RT, CT]) RunContext(ctx context.Context) error {
 	return nil
 }
 
+// Run runs the app until it finishes or SIGINT/SIGTERM is received.
+//
+// Instead of running the app, one of the built-in commands is run if requested by command line arguments:
+// "version", "config validate", "config dump", "healthcheck" or "help". Otherwise, a command registered
+// by AddCommand is run if selected.
+//
+// After the first signal the app is marked as draining and, once the drain delay passes, its runtime context
+// gets cancelled. Then the app is given the shutdown timeout to stop, after which the process exits with
+// a non-zero code. The second signal forces the process to exit immediately.
 func (r *Runner[RT, CT]) Run() error {
+	if ok, err := r.runCLI(); ok {
+		return err
+	}
+
+	if err := r.selectCommand(); err != nil {
+		return err
+	}
+
 	ctx, ctxC := context.WithCancel(context.Background())
 	defer ctxC()
 
+	sig := make(chan os.Signal, 2)
+	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sig)
+
+	r.initLogger()
+
+	done := make(chan error, 1)
 	go func() {
-		sig := make(chan os.Signal, 1)
-		signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
-		<-sig
-		ctxC()
+		done <- r.RunContext(ctx)
 	}()
 
-	return r.RunContext(ctx)
+	select {
+	case err := <-done:
+		return err
+	case s := <-sig:
+		r.rt.Log.Info().Str("signal", s.String()).Msg("shutting down")
+	}
+
+	if ok, err := r.waitDrain(done, sig); !ok {
+		return err
+	}
+
+	ctxC()
+
+	return r.waitShutdown(done, sig)
+}
+
+// logConfigError logs each of possibly joined config errors as a separate record.
+func (r *Runner[RT, CT]) logConfigError(err error, msg string) {
+	if jErr, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, err := range jErr.Unwrap() {
+			r.logConfigError(err, msg)
+		}
+		return
+	}
+
+	ev := r.rt.Log.Error().Err(err)
+
+	var cErr *ConfigError
+	if errors.As(err, &cErr) {
+		ev = ev.Str("source", cErr.Source).Str("field", cErr.Field)
+	}
+
+	ev.Msg(msg)
+}
+
+func (r *Runner[RT, CT]) initLogger() {
+	r.logOnce.Do(func() {
+		logLevel, err := r.envLogLevel()
+		if err != nil {
+			r.errs = append(r.errs, err)
+		}
+
+		r.rt.logLevel.init(logLevel)
+
+		writers := make([]io.Writer, 0, len(r.logWriters))
+		for _, w := range r.logWriters {
+			writers = append(writers, w.levelWriter())
+		}
+
+		lc := zerolog.New(zerolog.MultiLevelWriter(writers...)).Level(zerolog.TraceLevel).
+			With().Str("app", r.rt.AppName).Str("app_v", r.rt.AppVersion)
+		if r.rt.Command != "" {
+			lc = lc.Str("cmd", r.rt.Command)
+		}
+		r.rt.Log = lc.Logger()
+
+		r.rt.Log = r.rt.Log.Hook(&r.rt.logLevel)
+
+		if r.logSampler != nil {
+			r.logSampler.level = r.rt.LogLevel
+			r.rt.Log = r.rt.Log.Hook(r.logSampler)
+		}
+
+		if r.tracing {
+			r.rt.Log = r.rt.Log.Hook(traceHook{})
+		}
+	})
 }
 
 func isTerminal() bool {
@@ -1,9 +1,19 @@
 package runner_test
 
 import (
+	"context"
+	"errors"
 	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
 	"testing"
+	"time"
 
+	apperrors "github.com/ashep/go-app/errors"
 	"github.com/ashep/go-app/runner"
 	"github.com/ashep/go-app/testhttpserver"
 	"github.com/ashep/go-app/testlogger"
@@ -50,6 +60,107 @@ func TestRunner(main *testing.T) {
 	})
 }
 
+func TestRuntime_Register(main *testing.T) {
+	main.Run("Ok", func(t *testing.T) {
+		ctx, ctxC := context.WithCancel(t.Context())
+		rec := &componentRecorder{}
+
+		err := runner.New(func(rt *runner.Runtime[runCfg]) error {
+			for _, n := range []string{"db", "consumer", "http"} {
+				require.NoError(t, rt.Register(&componentMock{name: n, rec: rec}))
+			}
+			ctxC()
+			return nil
+		}).RunContext(ctx)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{
+			"start db", "start consumer", "start http",
+			"stop http", "stop consumer", "stop db",
+		}, rec.events())
+	})
+
+	main.Run("StartFailed", func(t *testing.T) {
+		rec := &componentRecorder{}
+		startErr := errors.New("start error")
+
+		err := runner.New(func(rt *runner.Runtime[runCfg]) error {
+			require.NoError(t, rt.Register(&componentMock{name: "db", rec: rec}))
+			require.Error(t, rt.Register(&componentMock{name: "http", rec: rec, startErr: startErr}))
+			<-rt.Ctx.Done()
+			return nil
+		}).RunContext(t.Context())
+
+		require.ErrorIs(t, err, startErr)
+		assert.EqualError(t, err, "start http: start error")
+		assert.Equal(t, []string{"start db", "start http", "stop db"}, rec.events())
+	})
+
+	main.Run("StopFailedAndTimedOut", func(t *testing.T) {
+		ctx, ctxC := context.WithCancel(t.Context())
+		rec := &componentRecorder{}
+		stopErr := errors.New("stop error")
+
+		err := runner.New(func(rt *runner.Runtime[runCfg]) error {
+			require.NoError(t, rt.Register(&componentMock{name: "db", rec: rec, stopErr: stopErr}))
+			require.NoError(t, rt.Register(&componentMock{name: "http", rec: rec, stopHang: true}))
+			ctxC()
+			return nil
+		}).SetComponentStopTimeout(time.Millisecond * 50).RunContext(ctx)
+
+		require.ErrorIs(t, err, stopErr)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Equal(t, []string{"start db", "start http", "stop http", "stop db"}, rec.events())
+	})
+}
+
+type componentRecorder struct {
+	mux sync.Mutex
+	ev  []string
+}
+
+func (r *componentRecorder) add(ev string) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.ev = append(r.ev, ev)
+}
+
+func (r *componentRecorder) events() []string {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	return r.ev
+}
+
+type componentMock struct {
+	name      string
+	rec       *componentRecorder
+	startErr  error
+	startHang chan struct{}
+	stopErr   error
+	stopHang  bool
+}
+
+func (c *componentMock) Name() string {
+	return c.name
+}
+
+func (c *componentMock) Start(_ context.Context) error {
+	c.rec.add("start " + c.name)
+	if c.startHang != nil {
+		<-c.startHang
+	}
+	return c.startErr
+}
+
+func (c *componentMock) Stop(ctx context.Context) error {
+	c.rec.add("stop " + c.name)
+	if c.stopHang {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	return c.stopErr
+}
+
 func newRunMock(t *testing.T) func(rt *runner.Runtime[runCfg]) error {
 	return func(rt *runner.Runtime[runCfg]) error {
 		rt.Log.Info().Msg("test log message")
@@ -74,3 +185,266 @@ func newLogServerMock(t *testing.T) *testhttpserver.Server {
 	s.Run()
 	return s
 }
+
+func TestRunner_Run(main *testing.T) {
+	newStuckRun := func(started chan<- struct{}) func(rt *runner.Runtime[runCfg]) error {
+		return func(rt *runner.Runtime[runCfg]) error {
+			close(started)
+			<-rt.Ctx.Done()
+			select {} // ignores cancellation
+		}
+	}
+
+	main.Run("Ok", func(t *testing.T) {
+		started := make(chan struct{})
+		errC := make(chan error)
+
+		go func() {
+			errC <- runner.New(func(rt *runner.Runtime[runCfg]) error {
+				close(started)
+				<-rt.Ctx.Done()
+				return nil
+			}).SetShutdownTimeout(time.Second).Run()
+		}()
+
+		<-started
+		require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGTERM))
+		require.NoError(t, <-errC)
+	})
+
+	main.Run("DrainDelay", func(t *testing.T) {
+		t.Setenv("FOO_DRAIN_DELAY", "100ms")
+
+		started := make(chan *runner.Runtime[runCfg], 1)
+		errC := make(chan error)
+
+		go func() {
+			errC <- runner.New(func(rt *runner.Runtime[runCfg]) error {
+				rt.MarkReady()
+				started <- rt
+				<-rt.Ctx.Done()
+				return nil
+			}).SetAppName("foo").SetDrainDelay(time.Hour).Run()
+		}()
+
+		rt := <-started
+		require.True(t, rt.IsReady())
+
+		sigAt := time.Now()
+		require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGTERM))
+
+		assert.Eventually(t, func() bool {
+			return rt.Phase() == runner.PhaseDraining
+		}, time.Second, time.Millisecond)
+		assert.False(t, rt.IsReady())
+		assert.NoError(t, rt.Ctx.Err())
+
+		require.NoError(t, <-errC)
+		assert.GreaterOrEqual(t, time.Since(sigAt), time.Millisecond*100)
+	})
+
+	main.Run("ShutdownTimeout", func(t *testing.T) {
+		exitCode := make(chan int, 1)
+		defer runner.SetExit(func(code int) { exitCode <- code })()
+
+		t.Setenv("FOO_SHUTDOWN_TIMEOUT", "50ms")
+
+		l := testlogger.New(t)
+		w := &bufferedLogWriter{}
+		started := make(chan struct{})
+		errC := make(chan error)
+
+		go func() {
+			errC <- runner.New(newStuckRun(started)).
+				SetAppName("foo").
+				SetShutdownTimeout(time.Hour).
+				AddLogWriter(l.Logger()).
+				AddLogWriter(w).
+				Run()
+		}()
+
+		<-started
+		require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGTERM))
+		require.ErrorIs(t, <-errC, runner.ErrShutdownTimeout)
+		assert.Equal(t, 1, <-exitCode)
+		l.AssertContains(`"message":"shutdown timed out"`)
+		assert.Contains(t, w.flushed.String(), `"message":"shutdown timed out"`)
+	})
+
+	main.Run("ShutdownTimeoutWhileStarting", func(t *testing.T) {
+		exitCode := make(chan int, 1)
+		defer runner.SetExit(func(code int) { exitCode <- code })()
+
+		l := testlogger.New(t)
+		rec := &componentRecorder{}
+		release := make(chan struct{})
+		defer close(release)
+		errC := make(chan error)
+
+		go func() {
+			errC <- runner.New(func(rt *runner.Runtime[runCfg]) error {
+				return rt.Register(&componentMock{name: "db", rec: rec, startHang: release})
+			}).
+				SetShutdownTimeout(time.Millisecond * 300).
+				AddLogWriter(l.Logger()).
+				Run()
+		}()
+
+		require.Eventually(t, func() bool {
+			return len(rec.events()) == 1
+		}, time.Second, time.Millisecond*10)
+		require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGTERM))
+
+		select {
+		case err := <-errC:
+			require.ErrorIs(t, err, runner.ErrShutdownTimeout)
+		case <-time.After(time.Second * 3):
+			t.Fatal("shutdown timeout was not respected")
+		}
+		assert.Equal(t, 1, <-exitCode)
+		l.AssertContains(`"message":"shutdown timed out"`)
+	})
+
+	main.Run("ForcedShutdownWhileStarting", func(t *testing.T) {
+		exitCode := make(chan int, 1)
+		defer runner.SetExit(func(code int) { exitCode <- code })()
+
+		l := testlogger.New(t)
+		rec := &componentRecorder{}
+		release := make(chan struct{})
+		defer close(release)
+		errC := make(chan error)
+
+		go func() {
+			errC <- runner.New(func(rt *runner.Runtime[runCfg]) error {
+				require.NoError(t, rt.Register(&componentMock{name: "db", rec: rec}))
+				return rt.Register(&componentMock{name: "http", rec: rec, startHang: release})
+			}).
+				AddLogWriter(l.Logger()).
+				Run()
+		}()
+
+		require.Eventually(t, func() bool {
+			return len(rec.events()) == 2
+		}, time.Second, time.Millisecond*10)
+		require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGTERM))
+		assert.Eventually(t, func() bool {
+			return strings.Contains(l.Content(), `"message":"shutting down"`)
+		}, time.Second, time.Millisecond*10)
+		require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGINT))
+
+		select {
+		case err := <-errC:
+			require.ErrorIs(t, err, runner.ErrForcedShutdown)
+		case <-time.After(time.Second * 3):
+			t.Fatal("forced shutdown did not happen")
+		}
+		assert.Equal(t, 1, <-exitCode)
+		l.AssertContains(`"components":["db"]`)
+	})
+
+	main.Run("ForcedShutdown", func(t *testing.T) {
+		exitCode := make(chan int, 1)
+		defer runner.SetExit(func(code int) { exitCode <- code })()
+
+		l := testlogger.New(t)
+		w := &bufferedLogWriter{}
+		started := make(chan struct{})
+		errC := make(chan error)
+
+		go func() {
+			errC <- runner.New(newStuckRun(started)).
+				AddLogWriter(l.Logger()).
+				AddLogWriter(w).
+				Run()
+		}()
+
+		<-started
+		require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGTERM))
+		assert.Eventually(t, func() bool {
+			return strings.Contains(l.Content(), `"message":"shutting down"`)
+		}, time.Second, time.Millisecond*10)
+		require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGINT))
+		require.ErrorIs(t, <-errC, runner.ErrForcedShutdown)
+		assert.Equal(t, 1, <-exitCode)
+		l.AssertContains(`"message":"forced shutdown"`)
+		assert.Contains(t, w.flushed.String(), `"message":"forced shutdown"`)
+	})
+}
+
+func TestRunner_RunContext(main *testing.T) {
+	main.Run("InvalidShutdownEnv", func(t *testing.T) {
+		t.Setenv("APP_SHUTDOWN_TIMEOUT", "30")
+		t.Setenv("FOO_DRAIN_DELAY", "soon")
+
+		err := runner.New(func(rt *runner.Runtime[runCfg]) error {
+			return nil
+		}).SetAppName("foo").RunContext(t.Context())
+
+		var cfgErr *runner.ConfigError
+		require.ErrorAs(t, err, &cfgErr)
+		assert.EqualError(t, err, "config APP_: SHUTDOWN_TIMEOUT: time: missing unit in duration \"30\"\n"+
+			"config FOO_: DRAIN_DELAY: time: invalid duration \"soon\"")
+	})
+
+	main.Run("ConfigLoadFailed", func(t *testing.T) {
+		l := testlogger.New(t)
+		p := filepath.Join(t.TempDir(), "config.json")
+		require.NoError(t, os.WriteFile(p, []byte("{]"), 0o600))
+
+		t.Setenv("APP_KEY2", "")
+		t.Setenv("FOO_INT", "notAnInt")
+
+		err := runner.New(func(rt *runner.Runtime[envCfg]) error {
+			t.Fatal("run must not be called")
+			return nil
+		}).
+			SetAppName("foo").
+			LoadConfigFile(p).
+			LoadEnvConfig().
+			AddLogWriter(l.Logger()).
+			RunContext(t.Context())
+
+		var cErr *runner.ConfigError
+		require.ErrorAs(t, err, &cErr)
+		assert.Equal(t, p, cErr.Source)
+
+		assert.EqualError(t, err, "config "+p+": invalid character ']' looking for beginning of object key string\n"+
+			`config FOO_: Int: strconv.ParseInt: parsing "notAnInt": invalid syntax`)
+
+		l.AssertContains(`"field":"Int","level":"error","message":"config load failed","source":"FOO_"`)
+		l.AssertContains(`"field":"","level":"error","message":"config load failed","source":"` + p + `"`)
+	})
+
+	main.Run("ConfigValidationFailed", func(t *testing.T) {
+		l := testlogger.New(t)
+
+		err := runner.New(func(rt *runner.Runtime[envCfg]) error {
+			t.Fatal("run must not be called")
+			return nil
+		}).
+			AddLogWriter(l.Logger()).
+			RunContext(t.Context())
+
+		assert.EqualError(t, err, "config validation: Int: must be positive")
+		l.AssertContains(`"field":"Int","level":"error","message":"config validation failed","source":"validation"`)
+	})
+}
+
+type envCfg struct {
+	Int int
+}
+
+func (c *envCfg) Validate() error {
+	if c.Int <= 0 {
+		return apperrors.NewInvalidArg("Int", "must be positive")
+	}
+	return nil
+}
+
+func TestDefaultServeMux(main *testing.T) {
+	main.Run("NoPprof", func(t *testing.T) {
+		_, pattern := http.DefaultServeMux.Handler(httptest.NewRequest(http.MethodGet, "/debug/pprof/", nil))
+		assert.Empty(t, pattern)
+	})
+}
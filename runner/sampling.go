@@ -0,0 +1,169 @@
+package runner
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	defaultLogSamplingReportPeriod = time.Minute
+
+	logSamplingReportMsg = "log records dropped by sampling"
+)
+
+// LogSampling describes how log records of a level are sampled.
+//
+// Records are grouped by message. Each group passes Burst records per Period, after which only every
+// Every-th record is passed until the period ends. Zero Every drops all records over the burst.
+type LogSampling struct {
+	Burst  uint64
+	Period time.Duration
+	Every  uint64
+}
+
+// SetLogSampling enables sampling of log records of the given level.
+//
+// Numbers of dropped records are periodically reported to the log, so nothing disappears silently.
+func (r *Runner[RT, CT]) SetLogSampling(lvl zerolog.Level, s LogSampling) *Runner[RT, CT] {
+	if r.logSampler == nil {
+		r.logSampler = newLogSampler()
+	}
+
+	r.logSampler.rules[lvl] = s
+
+	return r
+}
+
+// SetLogSamplingReportPeriod sets how often numbers of records dropped by sampling are reported.
+// Defaults to 1 minute. Non-positive values keep the default.
+func (r *Runner[RT, CT]) SetLogSamplingReportPeriod(d time.Duration) *Runner[RT, CT] {
+	if d <= 0 {
+		d = defaultLogSamplingReportPeriod
+	}
+
+	r.logSamplingReportPeriod = d
+
+	return r
+}
+
+// startLogSamplingReporter starts reporting dropped records and returns a function which makes
+// the final report and stops reporting.
+func (r *Runner[RT, CT]) startLogSamplingReporter() func() {
+	ctx, ctxC := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		r.logSampler.runReporter(ctx, r.rt.Log, r.logSamplingReportPeriod)
+	}()
+
+	return func() {
+		ctxC()
+		<-done
+	}
+}
+
+type logSampleKey struct {
+	lvl zerolog.Level
+	msg string
+}
+
+type logSampleBucket struct {
+	start time.Time
+	n     uint64
+}
+
+// logSampler is a zerolog hook discarding records according to per-level rules.
+type logSampler struct {
+	mux     sync.Mutex
+	rules   map[zerolog.Level]LogSampling
+	buckets map[logSampleKey]*logSampleBucket
+	dropped map[logSampleKey]uint64
+	level   func() zerolog.Level // current log level
+	now     func() time.Time
+}
+
+func newLogSampler() *logSampler {
+	return &logSampler{
+		rules:   make(map[zerolog.Level]LogSampling),
+		buckets: make(map[logSampleKey]*logSampleBucket),
+		dropped: make(map[logSampleKey]uint64),
+		level:   zerolog.GlobalLevel,
+		now:     time.Now,
+	}
+}
+
+func (s *logSampler) Run(e *zerolog.Event, lvl zerolog.Level, msg string) {
+	// Records below the current level are dropped anyway and must not use up the burst
+	if msg == logSamplingReportMsg || (lvl != zerolog.NoLevel && lvl < s.level()) {
+		return
+	}
+
+	rule, ok := s.rules[lvl]
+	if !ok {
+		return
+	}
+
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	k := logSampleKey{lvl: lvl, msg: msg}
+	now := s.now()
+
+	b, ok := s.buckets[k]
+	if !ok || now.Sub(b.start) >= rule.Period {
+		b = &logSampleBucket{start: now}
+		s.buckets[k] = b
+	}
+
+	b.n++
+	if b.n <= rule.Burst || (rule.Every > 0 && (b.n-rule.Burst)%rule.Every == 0) {
+		return
+	}
+
+	s.dropped[k]++
+	e.Discard()
+}
+
+// report logs numbers of dropped records and resets them.
+func (s *logSampler) report(l zerolog.Logger) {
+	s.mux.Lock()
+	dropped := s.dropped
+	s.dropped = make(map[logSampleKey]uint64)
+
+	// Forget stale buckets to not grow forever
+	now := s.now()
+	for k, b := range s.buckets {
+		if now.Sub(b.start) >= s.rules[k.lvl].Period {
+			delete(s.buckets, k)
+		}
+	}
+	s.mux.Unlock()
+
+	for k, n := range dropped {
+		l.Warn().
+			Str("sampled_level", k.lvl.String()).
+			Str("sampled_message", k.msg).
+			Uint64("dropped", n).
+			Msg(logSamplingReportMsg)
+	}
+}
+
+// runReporter periodically reports dropped records until ctx is done.
+func (s *logSampler) runReporter(ctx context.Context, l zerolog.Logger, period time.Duration) {
+	t := time.NewTicker(period)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.report(l)
+			return
+		case <-t.C:
+			s.report(l)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package runner_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ashep/go-app/runner"
+	"github.com/ashep/go-app/testlogger"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunner_SetLogSampling(main *testing.T) {
+	main.Run("Ok", func(t *testing.T) {
+		l := testlogger.New(t)
+
+		err := runner.New(func(rt *runner.Runtime[runCfg]) error {
+			for range 10 {
+				rt.Log.Warn().Msg("request failed")
+				rt.Log.Warn().Msg("other warning")
+				rt.Log.Info().Msg("info message")
+			}
+			return nil
+		}).
+			SetLogSampling(zerolog.WarnLevel, runner.LogSampling{Burst: 2, Period: time.Hour, Every: 4}).
+			SetLogSamplingReportPeriod(time.Hour).
+			AddLogWriter(l.Logger()).
+			RunContext(t.Context())
+		require.NoError(t, err)
+
+		// 2 from the burst, then every 4th of the remaining 8
+		assert.Equal(t, 4, strings.Count(l.Content(), `"message":"request failed"`))
+		assert.Equal(t, 4, strings.Count(l.Content(), `"message":"other warning"`))
+		assert.Equal(t, 10, strings.Count(l.Content(), `"message":"info message"`))
+
+		l.AssertContains(`"dropped":6,"level":"warn","message":"log records dropped by sampling",` +
+			`"sampled_level":"warn","sampled_message":"request failed"`)
+		l.AssertContains(`"dropped":6,"level":"warn","message":"log records dropped by sampling",` +
+			`"sampled_level":"warn","sampled_message":"other warning"`)
+	})
+
+	main.Run("BelowLevel", func(t *testing.T) {
+		l := testlogger.New(t)
+
+		err := runner.New(func(rt *runner.Runtime[runCfg]) error {
+			for range 5 {
+				rt.Log.Debug().Msg("debug message")
+			}
+
+			rt.SetLogLevel(zerolog.DebugLevel, 0)
+			rt.Log.Debug().Msg("debug message")
+
+			return nil
+		}).
+			SetLogSampling(zerolog.DebugLevel, runner.LogSampling{Burst: 1, Period: time.Hour}).
+			SetLogSamplingReportPeriod(time.Hour).
+			AddLogWriter(l.Logger()).
+			RunContext(t.Context())
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, strings.Count(l.Content(), `"message":"debug message"`))
+		assert.NotContains(t, l.Content(), `"sampled_message":"debug message"`)
+	})
+
+	main.Run("ZeroReportPeriod", func(t *testing.T) {
+		err := runner.New(func(rt *runner.Runtime[runCfg]) error {
+			rt.Log.Warn().Msg("warn message")
+			return nil
+		}).
+			SetLogSampling(zerolog.WarnLevel, runner.LogSampling{Burst: 1, Period: time.Hour}).
+			SetLogSamplingReportPeriod(0).
+			RunContext(t.Context())
+		require.NoError(t, err)
+	})
+
+	main.Run("PeriodicReport", func(t *testing.T) {
+		l := testlogger.New(t)
+
+		go func() {
+			_ = runner.New(func(rt *runner.Runtime[runCfg]) error {
+				for range 3 {
+					rt.Log.Error().Msg("error message")
+				}
+				<-rt.Ctx.Done()
+				return nil
+			}).
+				SetLogSampling(zerolog.ErrorLevel, runner.LogSampling{Burst: 1, Period: time.Hour}).
+				SetLogSamplingReportPeriod(time.Millisecond * 10).
+				AddLogWriter(l.Logger()).
+				RunContext(t.Context())
+		}()
+
+		assert.Eventually(t, func() bool {
+			return strings.Contains(l.Content(), `"dropped":2,"level":"warn","message":"log records dropped by sampling"`)
+		}, time.Second, time.Millisecond*10)
+		assert.Equal(t, 1, strings.Count(l.Content(), `"message":"error message"`))
+	})
+}
@@ -0,0 +1,151 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/ashep/go-app/prommetrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/robfig/cron/v3"
+)
+
+type OverlapPolicy int
+
+const (
+	// OverlapSkip skips runs which were due while the previous run was in progress.
+	OverlapSkip OverlapPolicy = iota
+
+	// OverlapCatchUp makes runs which were due while the previous run was in progress
+	// to be performed one by one right after it.
+	OverlapCatchUp
+)
+
+type JobOption func(*job)
+
+// WithJitter delays each job run by a random duration in [0, d).
+func WithJitter(d time.Duration) JobOption {
+	return func(j *job) {
+		j.jitter = d
+	}
+}
+
+// WithOverlapPolicy sets what happens to runs which were due while the previous run was in progress.
+// Defaults to OverlapSkip.
+func WithOverlapPolicy(p OverlapPolicy) JobOption {
+	return func(j *job) {
+		j.overlap = p
+	}
+}
+
+type job struct {
+	name    string
+	sched   cron.Schedule
+	fn      func(ctx context.Context) error
+	jitter  time.Duration
+	overlap OverlapPolicy
+}
+
+// Schedule runs fn according to a cron expression, e.g. "*/5 * * * *", or a descriptor like "@hourly"
+// or "@every 1m30s", until the runtime context is done.
+//
+// Runs of the same job never overlap. Each run is logged and its duration is recorded to
+// the app_scheduled_job_duration_seconds histogram. An error returned by fn is only logged, while a panic in fn
+// crashes the app the same way as a panic in the run function.
+func (rt *Runtime[CT]) Schedule(name, spec string, fn func(ctx context.Context) error, opts ...JobOption) error {
+	sched, err := cron.ParseStandard(spec)
+	if err != nil {
+		return fmt.Errorf("parse schedule %q: %w", spec, err)
+	}
+
+	rt.scheduleJob(name, sched, fn, opts...)
+
+	return nil
+}
+
+// ScheduleEvery runs fn each interval until the runtime context is done. The interval must be positive.
+// See Schedule for details.
+func (rt *Runtime[CT]) ScheduleEvery(name string, interval time.Duration, fn func(ctx context.Context) error, opts ...JobOption) error {
+	if interval <= 0 {
+		return fmt.Errorf("invalid interval %s", interval)
+	}
+
+	rt.scheduleJob(name, intervalSchedule(interval), fn, opts...)
+
+	return nil
+}
+
+// intervalSchedule is like cron.ConstantDelaySchedule but is not rounded to seconds.
+type intervalSchedule time.Duration
+
+func (s intervalSchedule) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(s))
+}
+
+func (rt *Runtime[CT]) scheduleJob(name string, sched cron.Schedule, fn func(ctx context.Context) error, opts ...JobOption) {
+	j := &job{
+		name:  name,
+		sched: sched,
+		fn:    fn,
+	}
+
+	for _, opt := range opts {
+		opt(j)
+	}
+
+	rt.jobsWG.Add(1)
+	rt.jobCount.Add(1)
+	go func() {
+		defer rt.jobsWG.Done()
+		defer rt.jobCount.Add(-1)
+		rt.runJob(j)
+	}()
+}
+
+func (rt *Runtime[CT]) runJob(j *job) {
+	l := rt.Log.With().Str("job", j.name).Logger()
+
+	next := j.sched.Next(time.Now())
+	for {
+		delay := time.Until(next)
+		if j.jitter > 0 {
+			delay += rand.N(j.jitter)
+		}
+
+		t := time.NewTimer(delay)
+		select {
+		case <-rt.Ctx.Done():
+			t.Stop()
+			return
+		case <-t.C:
+		}
+
+		start := time.Now()
+		err := rt.protect(func() error { return j.fn(rt.Ctx) })
+		dur := time.Since(start)
+
+		result := "success"
+		if err != nil {
+			result = "failure"
+			l.Error().Err(err).Dur("duration", dur).Msg("scheduled job failed")
+		} else {
+			l.Info().Dur("duration", dur).Msg("scheduled job done")
+		}
+
+		lbs := prometheus.Labels{
+			"app":    rt.AppName,
+			"app_v":  rt.AppVersion,
+			"job":    j.name,
+			"result": result,
+		}
+		prommetrics.GetHistogram("app_scheduled_job_duration_seconds", "Scheduled job run duration.", lbs).
+			With(lbs).Observe(dur.Seconds())
+
+		if j.overlap == OverlapCatchUp {
+			next = j.sched.Next(next)
+		} else {
+			next = j.sched.Next(time.Now())
+		}
+	}
+}
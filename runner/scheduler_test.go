@@ -0,0 +1,132 @@
+package runner_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ashep/go-app/runner"
+	"github.com/ashep/go-app/testlogger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuntime_Schedule(main *testing.T) {
+	main.Run("InvalidSpec", func(t *testing.T) {
+		err := runner.New(func(rt *runner.Runtime[runCfg]) error {
+			return rt.Schedule("job", "* * *", func(ctx context.Context) error { return nil })
+		}).RunContext(t.Context())
+
+		assert.EqualError(t, err, `parse schedule "* * *": expected exactly 5 fields, found 3: [* * *]`)
+	})
+
+	main.Run("InvalidInterval", func(t *testing.T) {
+		err := runner.New(func(rt *runner.Runtime[runCfg]) error {
+			return rt.ScheduleEvery("job", 0, func(ctx context.Context) error { return nil })
+		}).RunContext(t.Context())
+
+		assert.EqualError(t, err, "invalid interval 0s")
+	})
+
+	main.Run("Every", func(t *testing.T) {
+		l := testlogger.New(t)
+		ctx, ctxC := context.WithCancel(t.Context())
+		cnt := &atomic.Int32{}
+
+		err := runner.New(func(rt *runner.Runtime[runCfg]) error {
+			err := rt.ScheduleEvery("counter", time.Millisecond*10, func(ctx context.Context) error {
+				if cnt.Add(1) == 2 {
+					return errors.New("job error")
+				}
+				return nil
+			}, runner.WithJitter(time.Millisecond))
+			require.NoError(t, err)
+
+			assert.Eventually(t, func() bool { return cnt.Load() >= 3 }, time.Second, time.Millisecond)
+			ctxC()
+
+			return nil
+		}).AddLogWriter(l.Logger()).RunContext(ctx)
+		require.NoError(t, err)
+
+		l.AssertContains(`"job":"counter","level":"info","message":"scheduled job done"`)
+		l.AssertContains(`"error":"job error","job":"counter","level":"error","message":"scheduled job failed"`)
+	})
+
+	main.Run("RunReturns", func(t *testing.T) {
+		ctx, ctxC := context.WithCancel(t.Context())
+		cnt := &atomic.Int32{}
+		done := make(chan error)
+
+		go func() {
+			done <- runner.New(func(rt *runner.Runtime[runCfg]) error {
+				return rt.ScheduleEvery("counter", time.Millisecond*10, func(ctx context.Context) error {
+					cnt.Add(1)
+					return nil
+				})
+			}).RunContext(ctx)
+		}()
+
+		assert.Eventually(t, func() bool { return cnt.Load() >= 3 }, time.Second, time.Millisecond)
+
+		ctxC()
+		require.NoError(t, <-done)
+	})
+
+	main.Run("OverlapPolicy", func(t *testing.T) {
+		run := func(t *testing.T, p runner.OverlapPolicy) int32 {
+			cnt := &atomic.Int32{}
+			ctx, ctxC := context.WithTimeout(t.Context(), time.Millisecond*250)
+			defer ctxC()
+
+			err := runner.New(func(rt *runner.Runtime[runCfg]) error {
+				err := rt.ScheduleEvery("slow", time.Millisecond*20, func(ctx context.Context) error {
+					// The first run takes as long as 5 intervals
+					if cnt.Add(1) == 1 {
+						time.Sleep(time.Millisecond * 100)
+					}
+					return nil
+				}, runner.WithOverlapPolicy(p))
+				require.NoError(t, err)
+
+				<-rt.Ctx.Done()
+				return nil
+			}).RunContext(ctx)
+			require.NoError(t, err)
+
+			return cnt.Load()
+		}
+
+		skipped := run(t, runner.OverlapSkip)
+		caughtUp := run(t, runner.OverlapCatchUp)
+
+		assert.Greater(t, caughtUp, skipped)
+	})
+
+	main.Run("WaitsForRunningJob", func(t *testing.T) {
+		ctx, ctxC := context.WithCancel(t.Context())
+		started := make(chan struct{})
+		stopped := &atomic.Bool{}
+
+		err := runner.New(func(rt *runner.Runtime[runCfg]) error {
+			err := rt.ScheduleEvery("long", time.Millisecond, func(ctx context.Context) error {
+				close(started)
+				<-ctx.Done()
+				time.Sleep(time.Millisecond * 10)
+				stopped.Store(true)
+				return ctx.Err()
+			})
+			require.NoError(t, err)
+
+			<-started
+			ctxC()
+
+			return nil
+		}).RunContext(ctx)
+		require.NoError(t, err)
+
+		assert.True(t, stopped.Load())
+	})
+}
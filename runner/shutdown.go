@@ -0,0 +1,137 @@
+package runner
+
+import (
+	"errors"
+	"os"
+	"time"
+)
+
+var (
+	ErrShutdownTimeout = errors.New("shutdown timed out")
+	ErrForcedShutdown  = errors.New("forced shutdown")
+
+	exit = os.Exit //nolint:gochecknoglobals // replaced in tests
+)
+
+// SetShutdownTimeout sets the time the app is given to stop after the first signal.
+//
+// The value can be overridden by APP_SHUTDOWN_TIMEOUT or <APPNAME>_SHUTDOWN_TIMEOUT env vars
+// holding a duration string, e.g. "30s". Zero means waiting forever.
+func (r *Runner[RT, CT]) SetShutdownTimeout(d time.Duration) *Runner[RT, CT] {
+	r.shutdownTimeout = d
+	return r
+}
+
+// SetDrainDelay sets the time the app keeps running after the first signal before the runtime context
+// gets cancelled. During the delay the app is in the draining phase, so readiness checks fail and load balancers
+// have time to stop sending traffic to the app.
+//
+// The value can be overridden by APP_DRAIN_DELAY or <APPNAME>_DRAIN_DELAY env vars
+// holding a duration string, e.g. "10s".
+func (r *Runner[RT, CT]) SetDrainDelay(d time.Duration) *Runner[RT, CT] {
+	r.drainDelay = d
+	return r
+}
+
+// getShutdownTimeout returns the shutdown timeout. An invalid env var value is reported by RunContext.
+func (r *Runner[RT, CT]) getShutdownTimeout() time.Duration {
+	d, _ := r.envDuration("SHUTDOWN_TIMEOUT", r.shutdownTimeout)
+	return d
+}
+
+// getDrainDelay returns the drain delay. An invalid env var value is reported by RunContext.
+func (r *Runner[RT, CT]) getDrainDelay() time.Duration {
+	d, _ := r.envDuration("DRAIN_DELAY", r.drainDelay)
+	return d
+}
+
+// shutdownEnvErrors returns errors of invalid shutdown timeout and drain delay env vars.
+func (r *Runner[RT, CT]) shutdownEnvErrors() []error {
+	var errs []error
+
+	for _, name := range []string{"SHUTDOWN_TIMEOUT", "DRAIN_DELAY"} {
+		if _, err := r.envDuration(name, 0); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errs
+}
+
+// envDuration returns the duration from APP_<name> or <APPNAME>_<name> env var or def if neither is set.
+// If a value cannot be parsed, def is returned along with an error.
+func (r *Runner[RT, CT]) envDuration(name string, def time.Duration) (time.Duration, error) {
+	res := def
+
+	for _, prefix := range []string{"APP", r.rt.AppName2} {
+		if v := os.Getenv(prefix + "_" + name); v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return def, &ConfigError{Source: prefix + "_", Field: name, Err: err}
+			}
+			res = d
+		}
+	}
+
+	return res, nil
+}
+
+// waitDrain marks the app as draining and waits for the drain delay to pass.
+// It returns false if the app stopped or the shutdown was forced in the meantime.
+func (r *Runner[RT, CT]) waitDrain(done <-chan error, sig <-chan os.Signal) (bool, error) {
+	r.rt.setPhase(PhaseDraining)
+
+	d := r.getDrainDelay()
+	if d <= 0 {
+		return true, nil
+	}
+
+	r.rt.Log.Info().Dur("delay", d).Msg("draining")
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return true, nil
+	case err := <-done:
+		return false, err
+	case s := <-sig:
+		r.forceShutdown(s)
+		return false, ErrForcedShutdown
+	}
+}
+
+// waitShutdown waits for the app to stop after the runtime context has been cancelled.
+func (r *Runner[RT, CT]) waitShutdown(done <-chan error, sig <-chan os.Signal) error {
+	var timeout <-chan time.Time
+	if d := r.getShutdownTimeout(); d > 0 {
+		t := time.NewTimer(d)
+		defer t.Stop()
+		timeout = t.C
+	}
+
+	select {
+	case err := <-done:
+		return err
+	case <-timeout:
+		r.rt.Log.Error().
+			Strs("components", r.rt.runningComponents()).
+			Msg("shutdown timed out")
+		r.flushLogWriters(false)
+		exit(1)
+		return ErrShutdownTimeout
+	case s := <-sig:
+		r.forceShutdown(s)
+		return ErrForcedShutdown
+	}
+}
+
+func (r *Runner[RT, CT]) forceShutdown(s os.Signal) {
+	r.rt.Log.Error().
+		Str("signal", s.String()).
+		Strs("components", r.rt.runningComponents()).
+		Msg("forced shutdown")
+	r.flushLogWriters(false)
+	exit(1)
+}
@@ -0,0 +1,214 @@
+package runner
+
+import (
+	"encoding/json"
+	"maps"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	ConfigSnapshotURLPath = "/debug/config"
+
+	configSourceDefault = "default"
+	configSourceFile    = "file"
+	configSourceEnv     = "env"
+)
+
+type httpServer interface {
+	Handle(pattern string, handler http.Handler)
+}
+
+// configLoader is a single step of building the config.
+type configLoader[CT any] struct {
+	source string        // one of configSource* constants
+	arg    func() string // file path or env vars prefix
+	load   func(*CT) error
+}
+
+// ConfigSnapshot is the effective config with secrets redacted.
+type ConfigSnapshot struct {
+	// Config is the config tree. Values of fields tagged `secret:"true"` are replaced by "***".
+	Config any `json:"config" yaml:"config"`
+
+	// Sources maps dot-separated field paths to where their values come from:
+	// "default", "file:<path>" or "env:<VAR_NAME>".
+	Sources map[string]string `json:"sources" yaml:"sources"`
+}
+
+func (s ConfigSnapshot) JSON() ([]byte, error) {
+	return json.Marshal(s)
+}
+
+func (s ConfigSnapshot) YAML() ([]byte, error) {
+	return yaml.Marshal(s)
+}
+
+// ConfigSnapshot returns the current config along with the provenance of its fields.
+//
+// The provenance is recorded when the config is loaded at startup or successfully reloaded.
+func (rt *Runtime[CT]) ConfigSnapshot() ConfigSnapshot {
+	rt.cfgMux.Lock()
+	cfg := rt.cfg.Load()
+	sources := maps.Clone(rt.cfgSources)
+	rt.cfgMux.Unlock()
+
+	return ConfigSnapshot{
+		Config:  redactConfig(cfg),
+		Sources: sources,
+	}
+}
+
+// apply runs the loader against cfg and records the source of each field it changed.
+func (l configLoader[CT]) apply(cfg *CT, sources map[string]string) error {
+	prev := configLeaves(cfg)
+	err := l.load(cfg)
+	updateConfigSources(sources, prev, configLeaves(cfg), l)
+	return err
+}
+
+// updateConfigSources sets the loader as the source of the fields whose values differ between prev and cur.
+func updateConfigSources[CT any](sources map[string]string, prev, cur map[string]configLeaf, l configLoader[CT]) {
+	for p, lf := range cur {
+		if pl, ok := prev[p]; ok && reflect.DeepEqual(pl.value, lf.value) {
+			continue
+		}
+
+		switch l.source {
+		case configSourceFile:
+			sources[p] = configSourceFile + ":" + l.arg()
+		case configSourceEnv:
+			sources[p] = configSourceEnv + ":" + l.arg() + "_" + lf.envKey
+		default:
+			sources[p] = configSourceDefault
+		}
+	}
+}
+
+// defaultConfigSources returns sources of a freshly created config, where every field is a default.
+func defaultConfigSources(cfg any) map[string]string {
+	leaves := configLeaves(cfg)
+
+	res := make(map[string]string, len(leaves))
+	for p := range leaves {
+		res[p] = configSourceDefault
+	}
+
+	return res
+}
+
+// RegisterConfigSnapshotServer serves the config snapshot as JSON, or YAML if requested with ?format=yaml.
+func (rt *Runtime[CT]) RegisterConfigSnapshotServer(srv httpServer) {
+	srv.Handle(ConfigSnapshotURLPath, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var (
+			b   []byte
+			err error
+		)
+
+		if r.URL.Query().Get("format") == "yaml" {
+			w.Header().Set("Content-Type", "application/yaml")
+			b, err = rt.ConfigSnapshot().YAML()
+		} else {
+			w.Header().Set("Content-Type", "application/json")
+			b, err = rt.ConfigSnapshot().JSON()
+		}
+
+		if err != nil {
+			rt.Log.Error().Err(err).Msg("config snapshot marshal failed")
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		_, _ = w.Write(b)
+	}))
+}
+
+type configLeaf struct {
+	envKey string
+	value  any
+}
+
+// configLeaves returns config leaf values keyed by their dot-separated paths.
+func configLeaves(cfg any) map[string]configLeaf {
+	res := make(map[string]configLeaf)
+	walkConfigLeaves(reflect.ValueOf(cfg), "", "", res)
+	return res
+}
+
+func walkConfigLeaves(v reflect.Value, path, envKey string, res map[string]configLeaf) {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v = reflect.Zero(v.Type().Elem())
+			continue
+		}
+		v = v.Elem()
+	}
+
+	if v.Kind() != reflect.Struct || v.Type().Implements(textMarshalerType) {
+		if v.IsValid() && v.CanInterface() {
+			res[path] = configLeaf{envKey: envKey, value: v.Interface()}
+		}
+		return
+	}
+
+	t := v.Type()
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() || fieldName(f) == "-" || f.Tag.Get("ignored") == "true" {
+			continue
+		}
+
+		p := fieldName(f)
+		if path != "" {
+			p = path + "." + p
+		}
+
+		// Mirrors how envconfig builds variable names
+		k := f.Name
+		if ok, _ := strconv.ParseBool(f.Tag.Get("split_words")); ok {
+			k = splitWords(k)
+		}
+		if tag := f.Tag.Get("envconfig"); tag != "" {
+			k = tag
+		}
+		k = strings.ToUpper(k)
+		if envKey != "" {
+			k = envKey + "_" + k
+		}
+
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			k = envKey
+		}
+
+		walkConfigLeaves(v.Field(i), p, k, res)
+	}
+}
+
+var (
+	wordsRegexp   = regexp.MustCompile("([^A-Z]+|[A-Z]+[^A-Z]+|[A-Z]+)")
+	acronymRegexp = regexp.MustCompile("([A-Z]+)([A-Z][^A-Z]+)")
+)
+
+// splitWords joins camel case words of a field name with underscores the same way envconfig does
+// for fields tagged `split_words:"true"`, e.g. "ReadHeaderTimeout" becomes "Read_Header_Timeout".
+func splitWords(name string) string {
+	var words []string
+	for _, w := range wordsRegexp.FindAllString(name, -1) {
+		if m := acronymRegexp.FindStringSubmatch(w); len(m) == 3 {
+			words = append(words, m[1], m[2])
+		} else {
+			words = append(words, w)
+		}
+	}
+
+	if len(words) == 0 {
+		return name
+	}
+
+	return strings.Join(words, "_")
+}
@@ -0,0 +1,145 @@
+package runner_test
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ashep/go-app/httpserver"
+	"github.com/ashep/go-app/runner"
+	"github.com/ashep/go-app/testhttpserver"
+	"github.com/ashep/go-app/testlogger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type snapshotCfg struct {
+	Name     string `yaml:"name" json:"name"`
+	Port     int    `yaml:"port" json:"port"`
+	Password string `yaml:"password" json:"password" secret:"true"`
+	DB       struct {
+		Host string `yaml:"host" json:"host"`
+	} `yaml:"db" json:"db"`
+}
+
+type splitWordsCfg struct {
+	HTTP   httpserver.Config `yaml:"http"`
+	DBHost string            `yaml:"db_host" split_words:"true"`
+}
+
+func TestRuntime_ConfigSnapshot(main *testing.T) {
+	main.Run("Ok", func(t *testing.T) {
+		p := filepath.Join(t.TempDir(), "config.yaml")
+		require.NoError(t, os.WriteFile(p, []byte("port: 8080\ndb:\n  host: db.local\n"), 0o600))
+
+		t.Setenv("APP_PASSWORD", "aPassword")
+		t.Setenv("FOO_DB_HOST", "db.remote")
+		t.Setenv("APP_DEBUG", "1")
+
+		l := testlogger.New(t)
+		var snap runner.ConfigSnapshot
+
+		err := runner.New(func(rt *runner.Runtime[snapshotCfg]) error {
+			snap = rt.ConfigSnapshot()
+			return nil
+		}).
+			SetAppName("foo").
+			SetConfig(snapshotCfg{Name: "aName"}).
+			LoadConfigFile(p).
+			LoadEnvConfig().
+			AddLogWriter(l.Logger()).
+			RunContext(t.Context())
+		require.NoError(t, err)
+
+		assert.Equal(t, map[string]string{
+			"name":     "default",
+			"port":     "file:" + p,
+			"password": "env:APP_PASSWORD",
+			"db.host":  "env:FOO_DB_HOST",
+		}, snap.Sources)
+
+		b, err := snap.JSON()
+		require.NoError(t, err)
+		assert.JSONEq(t, `{
+			"config": {"name": "aName", "port": 8080, "password": "***", "db": {"host": "db.remote"}},
+			"sources": {"name": "default", "port": "file:`+p+`", "password": "env:APP_PASSWORD", "db.host": "env:FOO_DB_HOST"}
+		}`, string(b))
+
+		l.AssertContains(`"message":"effective config"`)
+		l.AssertContains(`"password":"***"`)
+		assert.NotContains(t, l.Content(), "aPassword")
+	})
+
+	main.Run("RecordedAtLoad", func(t *testing.T) {
+		t.Setenv("FOO_DB_HOST", "db.remote")
+
+		var snap runner.ConfigSnapshot
+
+		err := runner.New(func(rt *runner.Runtime[snapshotCfg]) error {
+			require.NoError(t, os.Unsetenv("FOO_DB_HOST"))
+			snap = rt.ConfigSnapshot()
+			return nil
+		}).
+			SetAppName("foo").
+			LoadEnvConfig().
+			RunContext(t.Context())
+		require.NoError(t, err)
+
+		assert.Equal(t, "env:FOO_DB_HOST", snap.Sources["db.host"])
+		assert.Equal(t, "default", snap.Sources["port"])
+	})
+
+	main.Run("SplitWords", func(t *testing.T) {
+		t.Setenv("APP_HTTP_READ_HEADER_TIMEOUT", "3s")
+		t.Setenv("APP_DB_HOST", "aHost")
+
+		var snap runner.ConfigSnapshot
+
+		err := runner.New(func(rt *runner.Runtime[splitWordsCfg]) error {
+			snap = rt.ConfigSnapshot()
+			assert.Equal(t, time.Second*3, rt.Config().HTTP.ReadHeaderTimeout)
+			return nil
+		}).
+			LoadEnvConfig().
+			RunContext(t.Context())
+		require.NoError(t, err)
+
+		assert.Equal(t, "env:APP_HTTP_READ_HEADER_TIMEOUT", snap.Sources["http.read_header_timeout"])
+		assert.Equal(t, "env:APP_DB_HOST", snap.Sources["db_host"])
+	})
+
+	main.Run("HTTP", func(t *testing.T) {
+		t.Setenv("APP_PASSWORD", "aPassword")
+
+		s := testhttpserver.New(t)
+		registered := &atomic.Bool{}
+
+		go func() {
+			_ = runner.New(func(rt *runner.Runtime[snapshotCfg]) error {
+				rt.RegisterConfigSnapshotServer(s)
+				registered.Store(true)
+				<-rt.Ctx.Done()
+				return nil
+			}).LoadEnvConfig().RunContext(t.Context())
+		}()
+
+		require.Eventually(t, registered.Load, time.Second, time.Millisecond*10)
+		s.Run()
+
+		res, err := http.Get(s.URL(runner.ConfigSnapshotURLPath + "?format=yaml"))
+		require.NoError(t, err)
+		defer func() {
+			_ = res.Body.Close()
+		}()
+
+		b, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "application/yaml", res.Header.Get("Content-Type"))
+		assert.Contains(t, string(b), "password: '***'")
+		assert.Contains(t, string(b), "password: env:APP_PASSWORD")
+	})
+}
@@ -0,0 +1,182 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/ashep/go-app/prommetrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	defaultRestartMinBackoff = time.Millisecond * 100
+	defaultRestartMaxBackoff = time.Second * 30
+)
+
+var ErrTooManyRestarts = errors.New("too many restarts")
+
+type RestartPolicy int
+
+const (
+	// RestartNever makes an error returned by a goroutine cancel the runtime context.
+	RestartNever RestartPolicy = iota
+
+	// RestartOnFailure restarts a goroutine if it returns an error or panics.
+	RestartOnFailure
+
+	// RestartAlways restarts a goroutine whenever it returns before the runtime context is done.
+	RestartAlways
+)
+
+type GoOption func(*goroutine)
+
+// WithRestartPolicy sets when a goroutine is restarted. Defaults to RestartNever.
+func WithRestartPolicy(p RestartPolicy) GoOption {
+	return func(g *goroutine) {
+		g.policy = p
+	}
+}
+
+// WithMaxRestarts limits the number of restarts within the window. Once the limit is exceeded, the goroutine
+// is handled as if it had the RestartNever policy. Zero n means no limit, which is the default.
+func WithMaxRestarts(n int, window time.Duration) GoOption {
+	return func(g *goroutine) {
+		g.maxRestarts = n
+		g.window = window
+	}
+}
+
+// WithRestartBackoff sets the delay before the first restart, which doubles with each subsequent restart
+// up to maxDelay. Defaults to 100ms and 30s.
+func WithRestartBackoff(minDelay, maxDelay time.Duration) GoOption {
+	return func(g *goroutine) {
+		g.minBackoff = minDelay
+		g.maxBackoff = maxDelay
+	}
+}
+
+type goroutine struct {
+	name        string
+	fn          func(ctx context.Context) error
+	policy      RestartPolicy
+	maxRestarts int
+	window      time.Duration
+	minBackoff  time.Duration
+	maxBackoff  time.Duration
+}
+
+// Go runs fn in a goroutine supervised by the runtime.
+//
+// The context passed to fn is cancelled when the app stops, and Runner.RunContext waits for the goroutine
+// to return. Unless the goroutine is restarted according to its restart policy, an error returned by fn
+// cancels the runtime context and is returned from Runner.RunContext. Panics in fn are handled the same way
+// as panics in the run function, unless the restart policy allows restarting the goroutine, in which case
+// the panic is logged and the goroutine is restarted. Restarts are logged and counted in the app_goroutine_restarts_total metric.
+func (rt *Runtime[CT]) Go(name string, fn func(ctx context.Context) error, opts ...GoOption) {
+	g := &goroutine{
+		name:       name,
+		fn:         fn,
+		minBackoff: defaultRestartMinBackoff,
+		maxBackoff: defaultRestartMaxBackoff,
+	}
+
+	for _, opt := range opts {
+		opt(g)
+	}
+
+	rt.goWG.Add(1)
+	rt.goCount.Add(1)
+
+	go func() {
+		defer rt.goWG.Done()
+		defer rt.goCount.Add(-1)
+
+		err := rt.supervise(g)
+		if err == nil {
+			return
+		}
+
+		rt.compMux.Lock()
+		rt.errs = append(rt.errs, fmt.Errorf("goroutine %s: %w", g.name, err))
+		rt.compMux.Unlock()
+
+		rt.cancel()
+	}()
+}
+
+// supervise runs the goroutine function restarting it according to the policy.
+func (rt *Runtime[CT]) supervise(g *goroutine) error {
+	l := rt.Log.With().Str("goroutine", g.name).Logger()
+
+	var (
+		restarts []time.Time
+		n        int
+	)
+
+	delay := g.minBackoff
+
+	onPanic := rt.crash
+	if g.policy != RestartNever {
+		onPanic = func(err *PanicError) {
+			l.Error().Str("panic", fmt.Sprint(err.Value)).Str("stack", string(err.Stack)).Msg("goroutine panicked")
+		}
+	}
+
+	for {
+		start := time.Now()
+		err := recoverPanic(func() error { return g.fn(rt.Ctx) }, onPanic)
+
+		if ctxErr := rt.Ctx.Err(); ctxErr != nil {
+			if errors.Is(err, ctxErr) {
+				return nil
+			}
+			return err
+		}
+
+		if g.policy == RestartNever || (g.policy == RestartOnFailure && err == nil) {
+			return err
+		}
+
+		now := time.Now()
+		if g.maxRestarts > 0 {
+			restarts = slices.DeleteFunc(restarts, func(t time.Time) bool { return now.Sub(t) > g.window })
+			if len(restarts) >= g.maxRestarts {
+				var pErr *PanicError
+				if errors.As(err, &pErr) {
+					rt.crash(pErr)
+				}
+				return errors.Join(ErrTooManyRestarts, err)
+			}
+			restarts = append(restarts, now)
+		}
+		n++
+
+		// A goroutine which has been running long enough is considered healthy
+		if now.Sub(start) > g.maxBackoff {
+			delay = g.minBackoff
+		}
+
+		l.Warn().Err(err).Int("restarts", n).Dur("delay", delay).Msg("goroutine restarting")
+
+		lbs := prometheus.Labels{
+			"app":       rt.AppName,
+			"app_v":     rt.AppVersion,
+			"goroutine": g.name,
+		}
+		prommetrics.GetCounter("app_goroutine_restarts_total", "Supervised goroutine restarts count.", lbs).
+			With(lbs).Inc()
+
+		t := time.NewTimer(delay)
+		select {
+		case <-rt.Ctx.Done():
+			t.Stop()
+			return nil
+		case <-t.C:
+		}
+
+		delay = min(delay*2, g.maxBackoff)
+	}
+}
@@ -0,0 +1,109 @@
+package runner_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ashep/go-app/runner"
+	"github.com/ashep/go-app/testlogger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuntime_Go(main *testing.T) {
+	main.Run("Ok", func(t *testing.T) {
+		ctx, ctxC := context.WithTimeout(t.Context(), time.Millisecond*50)
+		defer ctxC()
+
+		var stopped atomic.Bool
+		err := runner.New(func(rt *runner.Runtime[runCfg]) error {
+			rt.Go("aName", func(ctx context.Context) error {
+				<-ctx.Done()
+				time.Sleep(time.Millisecond * 10)
+				stopped.Store(true)
+				return ctx.Err()
+			})
+			return nil
+		}).RunContext(ctx)
+
+		require.NoError(t, err)
+		assert.True(t, stopped.Load())
+	})
+
+	main.Run("Error", func(t *testing.T) {
+		err := runner.New(func(rt *runner.Runtime[runCfg]) error {
+			rt.Go("aName", func(ctx context.Context) error {
+				return errors.New("anError")
+			})
+			<-rt.Ctx.Done()
+			return nil
+		}).RunContext(t.Context())
+
+		require.EqualError(t, err, "goroutine aName: anError")
+	})
+
+	main.Run("RestartOnFailure", func(t *testing.T) {
+		l := testlogger.New(t)
+
+		ctx, ctxC := context.WithTimeout(t.Context(), time.Millisecond*100)
+		defer ctxC()
+
+		var runs atomic.Int32
+		err := runner.New(func(rt *runner.Runtime[runCfg]) error {
+			rt.Go("aName", func(ctx context.Context) error {
+				if runs.Add(1) < 3 {
+					return errors.New("anError")
+				}
+				<-ctx.Done()
+				return nil
+			}, runner.WithRestartPolicy(runner.RestartOnFailure), runner.WithRestartBackoff(time.Millisecond, time.Second))
+			return nil
+		}).AddLogWriter(l.Logger()).RunContext(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, int32(3), runs.Load())
+		l.AssertContains(`"error":"anError","goroutine":"aName","level":"warn","message":"goroutine restarting","restarts":2`)
+	})
+
+	main.Run("RestartAlways", func(t *testing.T) {
+		ctx, ctxC := context.WithTimeout(t.Context(), time.Millisecond*100)
+		defer ctxC()
+
+		var runs atomic.Int32
+		err := runner.New(func(rt *runner.Runtime[runCfg]) error {
+			rt.Go("aName", func(ctx context.Context) error {
+				if runs.Add(1) < 3 {
+					return nil
+				}
+				<-ctx.Done()
+				return nil
+			}, runner.WithRestartPolicy(runner.RestartAlways), runner.WithRestartBackoff(time.Millisecond, time.Second))
+			return nil
+		}).RunContext(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, int32(3), runs.Load())
+	})
+
+	main.Run("TooManyRestarts", func(t *testing.T) {
+		var runs atomic.Int32
+		err := runner.New(func(rt *runner.Runtime[runCfg]) error {
+			rt.Go("aName", func(ctx context.Context) error {
+				runs.Add(1)
+				return errors.New("anError")
+			},
+				runner.WithRestartPolicy(runner.RestartOnFailure),
+				runner.WithRestartBackoff(time.Millisecond, time.Second),
+				runner.WithMaxRestarts(2, time.Minute),
+			)
+			return nil
+		}).RunContext(t.Context())
+
+		require.ErrorIs(t, err, runner.ErrTooManyRestarts)
+		assert.EqualError(t, err, "goroutine aName: too many restarts\nanError")
+		assert.Equal(t, int32(3), runs.Load())
+	})
+}
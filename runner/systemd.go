@@ -0,0 +1,64 @@
+package runner
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/ashep/go-app/systemd"
+)
+
+// EnableSystemd makes the runner notify systemd about the app readiness and shutdown via NOTIFY_SOCKET
+// and send watchdog keep-alive notifications if WATCHDOG_USEC is set.
+//
+// READY=1 is sent only when the app is marked ready, either by Runtime.MarkReady or automatically when the
+// run function returns leaving components, goroutines or jobs running. A Type=notify unit whose run function
+// blocks without calling MarkReady is killed by systemd once TimeoutStartSec elapses.
+func (r *Runner[RT, CT]) EnableSystemd() *Runner[RT, CT] {
+	r.systemd = true
+	return r
+}
+
+// SystemdListeners returns socket-activated listeners passed by systemd, suitable for httpserver.WithListener.
+func (rt *Runtime[CT]) SystemdListeners() ([]net.Listener, error) {
+	return systemd.Listeners()
+}
+
+func (r *Runner[RT, CT]) systemdNotify(state string) {
+	if _, err := systemd.Notify(state); err != nil {
+		r.rt.Log.Warn().Err(err).Str("state", state).Msg("systemd notify failed")
+	}
+}
+
+// startSystemdWatchdog starts sending watchdog notifications at half of the interval expected by systemd
+// and returns a function which stops it.
+func (r *Runner[RT, CT]) startSystemdWatchdog() func() {
+	interval, ok := systemd.WatchdogInterval()
+	if !ok {
+		return func() {}
+	}
+
+	ctx, ctxC := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		t := time.NewTicker(interval / 2)
+		defer t.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+				r.systemdNotify(systemd.StateWatchdog)
+			}
+		}
+	}()
+
+	return func() {
+		ctxC()
+		<-done
+	}
+}
@@ -0,0 +1,59 @@
+package runner_test
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ashep/go-app/runner"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunner_EnableSystemd(main *testing.T) {
+	main.Run("Ok", func(t *testing.T) {
+		p := filepath.Join(t.TempDir(), "notify.sock")
+		conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: p, Net: "unixgram"})
+		require.NoError(t, err)
+		defer func() {
+			_ = conn.Close()
+		}()
+
+		t.Setenv("NOTIFY_SOCKET", p)
+		t.Setenv("WATCHDOG_USEC", "20000")
+
+		var states []string
+		readDone := make(chan struct{})
+		go func() {
+			defer close(readDone)
+			b := make([]byte, 64)
+			for {
+				n, err := conn.Read(b)
+				if err != nil {
+					return
+				}
+				states = append(states, string(b[:n]))
+			}
+		}()
+
+		ctx, ctxC := context.WithTimeout(t.Context(), time.Millisecond*100)
+		defer ctxC()
+
+		err = runner.New(func(rt *runner.Runtime[runCfg]) error {
+			rt.MarkReady()
+			<-rt.Ctx.Done()
+			return nil
+		}).EnableSystemd().RunContext(ctx)
+		require.NoError(t, err)
+
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Millisecond*50)))
+		<-readDone
+
+		require.GreaterOrEqual(t, len(states), 3)
+		assert.Equal(t, "READY=1", states[0])
+		assert.Contains(t, states, "WATCHDOG=1")
+		assert.Contains(t, states, "STOPPING=1")
+	})
+}
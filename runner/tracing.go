@@ -0,0 +1,132 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	TracingExporterOTLP   = "otlp"
+	TracingExporterStdout = "stdout"
+
+	tracingShutdownTimeout = time.Second * 5
+)
+
+// EnableTracing makes the runner set up the global OpenTelemetry tracer provider and W3C trace context
+// propagator, and add trace_id and span_id fields to log records having a context with a span.
+//
+// The tracer provider is configured by env vars, where APP_ prefix can be replaced with <APPNAME>_:
+//   - APP_OTEL_EXPORTER: "otlp" (default) to export spans via OTLP/HTTP or "stdout" to print them;
+//   - APP_OTEL_ENDPOINT: OTLP collector base URL, e.g. "http://localhost:4318". If not set, the standard
+//     OTEL_EXPORTER_OTLP_* env vars are respected;
+//   - APP_OTEL_SAMPLE_RATIO: ratio of traces to sample, from 0 to 1. Defaults to 1.
+func (r *Runner[RT, CT]) EnableTracing() *Runner[RT, CT] {
+	r.tracing = true
+	return r
+}
+
+// Tracer returns a tracer named after the app.
+func (rt *Runtime[CT]) Tracer() trace.Tracer {
+	return otel.Tracer(rt.AppName)
+}
+
+// startTracing sets up the tracer provider and returns a function which flushes and shuts it down.
+func (r *Runner[RT, CT]) startTracing(ctx context.Context) (func(), error) {
+	env := func(name string) string {
+		var res string
+		for _, prefix := range []string{"APP", r.rt.AppName2} {
+			if v := os.Getenv(prefix + "_OTEL_" + name); v != "" {
+				res = v
+			}
+		}
+		return res
+	}
+
+	var (
+		exp sdktrace.SpanExporter
+		err error
+	)
+
+	switch e := env("EXPORTER"); e {
+	case "", TracingExporterOTLP:
+		var opts []otlptracehttp.Option
+		if ep := env("ENDPOINT"); ep != "" {
+			u, jErr := url.JoinPath(ep, "v1/traces")
+			if jErr != nil {
+				return nil, fmt.Errorf("invalid otel endpoint: %w", jErr)
+			}
+			opts = append(opts, otlptracehttp.WithEndpointURL(u))
+		}
+		exp, err = otlptracehttp.New(ctx, opts...)
+	case TracingExporterStdout:
+		exp, err = stdouttrace.New(stdouttrace.WithWriter(os.Stderr))
+	default:
+		return nil, fmt.Errorf("unknown otel exporter: %s", e)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("create otel exporter: %w", err)
+	}
+
+	ratio := 1.0
+	if v := env("SAMPLE_RATIO"); v != "" {
+		if ratio, err = strconv.ParseFloat(v, 64); err != nil {
+			return nil, fmt.Errorf("invalid otel sample ratio: %w", err)
+		}
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		attribute.String("service.name", r.rt.AppName),
+		attribute.String("service.version", r.rt.AppVersion),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("create otel resource: %w", err)
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exp),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+	)
+
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	return func() {
+		sCtx, sCtxC := context.WithTimeout(context.Background(), tracingShutdownTimeout)
+		defer sCtxC()
+
+		if err := tp.Shutdown(sCtx); err != nil {
+			r.rt.Log.Error().Err(err).Msg("tracer provider shutdown failed")
+		}
+	}, nil
+}
+
+// traceHook adds trace_id and span_id fields to log records having a context with a valid span.
+type traceHook struct{}
+
+func (traceHook) Run(e *zerolog.Event, _ zerolog.Level, _ string) {
+	sc := trace.SpanContextFromContext(e.GetCtx())
+	if !sc.IsValid() {
+		return
+	}
+
+	e.Str("trace_id", sc.TraceID().String()).Str("span_id", sc.SpanID().String())
+}
@@ -0,0 +1,91 @@
+package runner_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/ashep/go-app/runner"
+	"github.com/ashep/go-app/testlogger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	collectortrace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestRunner_EnableTracing(main *testing.T) {
+	main.Run("Ok", func(t *testing.T) {
+		var (
+			mux   sync.Mutex
+			spans = make(map[string]string) // span name -> service name
+		)
+
+		collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/v1/traces", r.URL.Path)
+
+			b, err := io.ReadAll(r.Body)
+			assert.NoError(t, err)
+
+			req := &collectortrace.ExportTraceServiceRequest{}
+			assert.NoError(t, proto.Unmarshal(b, req))
+
+			mux.Lock()
+			defer mux.Unlock()
+
+			for _, rs := range req.GetResourceSpans() {
+				var svc string
+				for _, a := range rs.GetResource().GetAttributes() {
+					if a.GetKey() == "service.name" {
+						svc = a.GetValue().GetStringValue()
+					}
+				}
+				for _, ss := range rs.GetScopeSpans() {
+					for _, s := range ss.GetSpans() {
+						spans[s.GetName()] = svc
+					}
+				}
+			}
+
+			w.Header().Set("Content-Type", "application/x-protobuf")
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer collector.Close()
+
+		t.Setenv("FOO_OTEL_ENDPOINT", collector.URL)
+
+		l := testlogger.New(t)
+
+		var traceID string
+		err := runner.New(func(rt *runner.Runtime[runCfg]) error {
+			ctx, span := rt.Tracer().Start(rt.Ctx, "aSpan")
+			defer span.End()
+
+			traceID = span.SpanContext().TraceID().String()
+			rt.Log.Info().Ctx(ctx).Msg("in span")
+			rt.Log.Info().Msg("no span")
+
+			return nil
+		}).SetAppName("foo").AddLogWriter(l.Logger()).EnableTracing().RunContext(t.Context())
+		require.NoError(t, err)
+
+		l.AssertContains(`"message":"in span","span_id":"`)
+		l.AssertContains(`"trace_id":"` + traceID + `"`)
+		assert.NotContains(t, l.Content(), `"message":"no span","span_id"`)
+
+		mux.Lock()
+		defer mux.Unlock()
+		assert.Equal(t, map[string]string{"aSpan": "foo"}, spans)
+	})
+
+	main.Run("UnknownExporter", func(t *testing.T) {
+		t.Setenv("APP_OTEL_EXPORTER", "unknown")
+
+		err := runner.New(func(rt *runner.Runtime[runCfg]) error {
+			t.Fatal("run must not be called")
+			return nil
+		}).EnableTracing().RunContext(t.Context())
+		require.EqualError(t, err, "unknown otel exporter: unknown")
+	})
+}
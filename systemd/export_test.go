@@ -0,0 +1,14 @@
+package systemd
+
+import "sync"
+
+func SetListenFDsStart(n int) {
+	listenFDsStart = n
+}
+
+func ResetListeners() {
+	listenersOnce = sync.Once{}
+	listeners = nil
+	namedListeners = nil
+	listenersErr = nil
+}
@@ -0,0 +1,144 @@
+package systemd
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const notifyTimeout = time.Second
+
+const (
+	StateReady    = "READY=1"
+	StateStopping = "STOPPING=1"
+	StateWatchdog = "WATCHDOG=1"
+)
+
+var (
+	listenFDsStart = 3 //nolint:gochecknoglobals // replaced in tests
+
+	listenersOnce  sync.Once                 //nolint:gochecknoglobals // see takeListeners
+	listeners      []net.Listener            //nolint:gochecknoglobals // see takeListeners
+	namedListeners map[string][]net.Listener //nolint:gochecknoglobals // see takeListeners
+	listenersErr   error                     //nolint:gochecknoglobals // see takeListeners
+)
+
+// Notify sends a state to the service manager over NOTIFY_SOCKET.
+// It returns false if the process is not run by systemd with notifications enabled.
+func Notify(state string) (bool, error) {
+	sockPath := os.Getenv("NOTIFY_SOCKET")
+	if sockPath == "" {
+		return false, nil
+	}
+
+	// Abstract namespace socket
+	if strings.HasPrefix(sockPath, "@") {
+		sockPath = "\x00" + sockPath[1:]
+	}
+
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: sockPath, Net: "unixgram"})
+	if err != nil {
+		return false, fmt.Errorf("dial notify socket: %w", err)
+	}
+
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	// Don't block the caller if the service manager doesn't read the socket
+	if err = conn.SetWriteDeadline(time.Now().Add(notifyTimeout)); err != nil {
+		return false, fmt.Errorf("set notify socket deadline: %w", err)
+	}
+
+	if _, err = conn.Write([]byte(state)); err != nil {
+		return false, fmt.Errorf("write to notify socket: %w", err)
+	}
+
+	return true, nil
+}
+
+// WatchdogInterval returns the interval the service manager expects WATCHDOG=1 notifications within.
+// It returns false if the watchdog is not enabled for the process.
+func WatchdogInterval() (time.Duration, bool) {
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return 0, false
+	}
+
+	usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+	if err != nil || usec <= 0 {
+		return 0, false
+	}
+
+	return time.Duration(usec) * time.Microsecond, true
+}
+
+// Listeners returns all the socket-activated listeners passed by the service manager
+// in the order of sockets definition.
+func Listeners() ([]net.Listener, error) {
+	takeListeners()
+	return listeners, listenersErr
+}
+
+// NamedListeners returns the socket-activated listeners passed by the service manager grouped
+// by names set with FileDescriptorName=. Unnamed listeners are grouped under the empty name.
+func NamedListeners() (map[string][]net.Listener, error) {
+	takeListeners()
+	return namedListeners, listenersErr
+}
+
+// takeListeners creates listeners from passed file descriptors once, as descriptors can be taken only once.
+func takeListeners() {
+	listenersOnce.Do(func() {
+		namedListeners = make(map[string][]net.Listener)
+
+		if pid := os.Getenv("LISTEN_PID"); pid == "" || pid != strconv.Itoa(os.Getpid()) {
+			return
+		}
+
+		n, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+		if err != nil || n <= 0 {
+			return
+		}
+
+		var names []string
+		if v := os.Getenv("LISTEN_FDNAMES"); v != "" {
+			names = strings.Split(v, ":")
+		}
+
+		// Prevent child processes from taking the same descriptors
+		_ = os.Unsetenv("LISTEN_PID")
+		_ = os.Unsetenv("LISTEN_FDS")
+		_ = os.Unsetenv("LISTEN_FDNAMES")
+
+		var errs []error
+		for i := range n {
+			fd := listenFDsStart + i
+			syscall.CloseOnExec(fd)
+
+			name := ""
+			if i < len(names) && names[i] != "unknown" {
+				name = names[i]
+			}
+
+			f := os.NewFile(uintptr(fd), "systemd-listen-fd-"+strconv.Itoa(fd))
+			l, err := net.FileListener(f)
+			_ = f.Close()
+
+			if err != nil {
+				errs = append(errs, fmt.Errorf("fd %d: %w", fd, err))
+				continue
+			}
+
+			listeners = append(listeners, l)
+			namedListeners[name] = append(namedListeners[name], l)
+		}
+
+		listenersErr = errors.Join(errs...)
+	})
+}
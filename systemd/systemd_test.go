@@ -0,0 +1,105 @@
+package systemd_test
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ashep/go-app/systemd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotify(main *testing.T) {
+	main.Run("NoSocket", func(t *testing.T) {
+		t.Setenv("NOTIFY_SOCKET", "")
+
+		ok, err := systemd.Notify(systemd.StateReady)
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	main.Run("Ok", func(t *testing.T) {
+		p := filepath.Join(t.TempDir(), "notify.sock")
+		conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: p, Net: "unixgram"})
+		require.NoError(t, err)
+		defer func() {
+			_ = conn.Close()
+		}()
+
+		t.Setenv("NOTIFY_SOCKET", p)
+
+		ok, err := systemd.Notify(systemd.StateReady)
+		require.NoError(t, err)
+		assert.True(t, ok)
+
+		b := make([]byte, 64)
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+		n, err := conn.Read(b)
+		require.NoError(t, err)
+		assert.Equal(t, systemd.StateReady, string(b[:n]))
+	})
+}
+
+func TestWatchdogInterval(main *testing.T) {
+	main.Run("Disabled", func(t *testing.T) {
+		t.Setenv("WATCHDOG_USEC", "")
+
+		_, ok := systemd.WatchdogInterval()
+		assert.False(t, ok)
+	})
+
+	main.Run("OtherPID", func(t *testing.T) {
+		t.Setenv("WATCHDOG_USEC", "1000000")
+		t.Setenv("WATCHDOG_PID", "1")
+
+		_, ok := systemd.WatchdogInterval()
+		assert.False(t, ok)
+	})
+
+	main.Run("Ok", func(t *testing.T) {
+		t.Setenv("WATCHDOG_USEC", "1500000")
+		t.Setenv("WATCHDOG_PID", strconv.Itoa(os.Getpid()))
+
+		d, ok := systemd.WatchdogInterval()
+		assert.True(t, ok)
+		assert.Equal(t, time.Millisecond*1500, d)
+	})
+}
+
+func TestListeners(main *testing.T) {
+	main.Run("Ok", func(t *testing.T) {
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer func() {
+			_ = lis.Close()
+		}()
+
+		f, err := lis.(*net.TCPListener).File()
+		require.NoError(t, err)
+
+		systemd.ResetListeners()
+		defer systemd.ResetListeners()
+
+		systemd.SetListenFDsStart(int(f.Fd()))
+		t.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()))
+		t.Setenv("LISTEN_FDS", "1")
+		t.Setenv("LISTEN_FDNAMES", "http")
+
+		named, err := systemd.NamedListeners()
+		require.NoError(t, err)
+		require.Len(t, named["http"], 1)
+
+		all, err := systemd.Listeners()
+		require.NoError(t, err)
+		require.Len(t, all, 1)
+		defer func() {
+			_ = all[0].Close()
+		}()
+		assert.Equal(t, lis.Addr().String(), all[0].Addr().String())
+		assert.Empty(t, os.Getenv("LISTEN_FDS"))
+	})
+}
@@ -26,10 +26,14 @@ type Server struct {
 }
 
 func New(t *testing.T) *Server {
+	s, err := httpserver.New(httpserver.WithRandomLocalAddr())
+	require.NoError(t, err)
+	require.NoError(t, s.Listen())
+
 	return &Server{
 		t:     t,
 		calls: make(map[string][]Call),
-		s:     httpserver.New(httpserver.WithRandomLocalAddr()),
+		s:     s,
 	}
 }
 
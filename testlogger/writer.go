@@ -4,10 +4,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"sync"
 )
 
 type BufWriter struct {
-	b strings.Builder
+	mux sync.Mutex
+	b   strings.Builder
 }
 
 func (w *BufWriter) Write(s []byte) (int, error) {
@@ -28,9 +30,15 @@ func (w *BufWriter) Write(s []byte) (int, error) {
 
 	out = append(out, '\n')
 
+	w.mux.Lock()
+	defer w.mux.Unlock()
+
 	return w.b.Write(out)
 }
 
 func (w *BufWriter) Content() string {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+
 	return w.b.String()
 }
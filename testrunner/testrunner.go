@@ -18,6 +18,8 @@ type Runner[RT func(*runner.Runtime[CT]) error, CT any] struct {
 	run              RT
 	waitStart        func(CT) bool
 	waitStartTimeout time.Duration
+	cfgPaths         []string
+	loadEnvCfg       bool
 	l                *testlogger.Logger
 }
 
@@ -30,6 +32,18 @@ func New[RT func(*runner.Runtime[CT]) error, CT any](t *testing.T, run RT, cfg C
 	}
 }
 
+// LoadConfigFile makes the app load config from a file on top of the config passed to New.
+func (r *Runner[RT, CT]) LoadConfigFile(path string) *Runner[RT, CT] {
+	r.cfgPaths = append(r.cfgPaths, path)
+	return r
+}
+
+// LoadEnvConfig makes the app load config from env vars on top of the config passed to New.
+func (r *Runner[RT, CT]) LoadEnvConfig() *Runner[RT, CT] {
+	r.loadEnvCfg = true
+	return r
+}
+
 func (r *Runner[RT, CT]) SetStartWaiter(w func(CT) bool, timeout time.Duration) *Runner[RT, CT] {
 	r.waitStart = w
 	r.waitStartTimeout = timeout
@@ -67,17 +81,12 @@ func (r *Runner[RT, CT]) SetHTTPReadyStartWaiter(url string, timeout time.Durati
 
 // Run runs the application and waits until it stops.
 func (r *Runner[RT, CT]) Run() error {
-	return runner.New(r.run).
-		SetConfig(r.cfg).
-		AddLogWriter(r.l.Logger()).
-		RunContext(r.t.Context())
+	return r.newRunner().RunContext(r.t.Context())
 }
 
 // Start starts the application in a separate goroutine.
 func (r *Runner[RT, CT]) Start() *Runner[RT, CT] {
-	rnr := runner.New(r.run).
-		SetConfig(r.cfg).
-		AddLogWriter(r.l.Logger())
+	rnr := r.newRunner()
 
 	go func() {
 		if err := rnr.RunContext(r.t.Context()); err != nil {
@@ -101,3 +110,19 @@ func (r *Runner[RT, CT]) Start() *Runner[RT, CT] {
 func (r *Runner[RT, CT]) Logger() *testlogger.Logger {
 	return r.l
 }
+
+func (r *Runner[RT, CT]) newRunner() *runner.Runner[RT, CT] {
+	rnr := runner.New(r.run).
+		SetConfig(r.cfg).
+		AddLogWriter(r.l.Logger())
+
+	for _, p := range r.cfgPaths {
+		rnr.LoadConfigFile(p)
+	}
+
+	if r.loadEnvCfg {
+		rnr.LoadEnvConfig()
+	}
+
+	return rnr
+}
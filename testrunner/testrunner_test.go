@@ -60,3 +60,22 @@ func runMock(rt *runner.Runtime[cfgMock]) error {
 
 	return nil
 }
+
+func TestRunner_LoadEnvConfig(main *testing.T) {
+	main.Run("Misconfigured", func(t *testing.T) {
+		t.Setenv("APP_NUM", "notANumber")
+
+		err := testrunner.New(t, func(rt *runner.Runtime[envCfgMock]) error {
+			return nil
+		}, envCfgMock{}).LoadEnvConfig().Run()
+
+		var cErr *runner.ConfigError
+		require.ErrorAs(t, err, &cErr)
+		assert.Equal(t, "APP_", cErr.Source)
+		assert.Equal(t, "Num", cErr.Field)
+	})
+}
+
+type envCfgMock struct {
+	Num int
+}
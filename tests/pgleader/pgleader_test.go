@@ -0,0 +1,61 @@
+//go:build functest
+
+package pgleader_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ashep/go-app/pgleader"
+	"github.com/ashep/go-app/testlogger"
+	"github.com/ashep/go-app/testpostgres"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestElector(main *testing.T) {
+	main.Run("Failover", func(t *testing.T) {
+		db := testpostgres.New(t).DB()
+		l := testlogger.New(t).Logger()
+
+		changes := &atomic.Int32{}
+		e1 := pgleader.New(db, "jobs", l,
+			pgleader.WithRetryInterval(time.Millisecond*20),
+			pgleader.WithOnChange(func(bool) { changes.Add(1) }),
+		)
+		e2 := pgleader.New(db, "jobs", l, pgleader.WithRetryInterval(time.Millisecond*20))
+
+		require.NoError(t, e1.Start(t.Context()))
+		require.Eventually(t, e1.IsLeader, time.Second*3, time.Millisecond*10)
+
+		require.NoError(t, e2.Start(t.Context()))
+		time.Sleep(time.Millisecond * 100)
+		assert.False(t, e2.IsLeader())
+
+		ctx, ctxC := context.WithTimeout(context.Background(), time.Second)
+		defer ctxC()
+		require.NoError(t, e1.Stop(ctx))
+		assert.False(t, e1.IsLeader())
+		assert.Equal(t, int32(2), changes.Load())
+
+		require.Eventually(t, e2.IsLeader, time.Second*3, time.Millisecond*10)
+		require.NoError(t, e2.Stop(ctx))
+	})
+
+	main.Run("DifferentNames", func(t *testing.T) {
+		db := testpostgres.New(t).DB()
+		l := testlogger.New(t).Logger()
+
+		e1 := pgleader.New(db, "jobs1", l)
+		e2 := pgleader.New(db, "jobs2", l)
+
+		require.NoError(t, e1.Start(t.Context()))
+		require.NoError(t, e2.Start(t.Context()))
+
+		require.Eventually(t, func() bool {
+			return e1.IsLeader() && e2.IsLeader()
+		}, time.Second*3, time.Millisecond*10)
+	})
+}